@@ -0,0 +1,311 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultMaxConcurrency is the default number of concurrent calls of Analyze.
+const defaultMaxConcurrency = 4
+
+// AnalyzeParams is the set of parameters that defines a document
+// and the endpoints it is analyzed with.
+type AnalyzeParams struct {
+	// Either Input, URL or Text is required, see Input.
+	Input Input
+	URL   string
+	Text  string
+
+	// Endpoints is the list of endpoints to call, any of the Endpoint constants.
+	Endpoints []Endpoint
+
+	// Title is the title of Text, required by summarize when it is called on its own.
+	Title string
+
+	// Language is the language of extract, concepts, hashtags, classify and classify by taxonomy,
+	// when they are called on their own.
+	Language Language
+
+	// Classes and NumberOfConcepts are the parameters of classify/unsupervised.
+	Classes          []string
+	NumberOfConcepts int
+
+	// Phrase and RelatedCount are the parameters of related.
+	Phrase       string
+	RelatedCount int
+
+	// MaxConcurrency is the maximum number of calls sent at the same time. Default is 4.
+	MaxConcurrency int
+}
+
+// AnalyzeResponse is the result of Analyze.
+// The result of an endpoint that failed is left empty, and its error is in Errors.
+type AnalyzeResponse struct {
+	Text            string
+	Article         ExtractResponse
+	Summary         SummarizeResponse
+	Concepts        ConceptsResponse
+	Entities        EntitiesResponse
+	Hashtags        HashtagsResponse
+	Language        LanguageResponse
+	Sentiment       SentimentResponse
+	Classifications ClassifyResponse
+
+	Microformats               MicroformatsResponse
+	ImageTags                  ImageTagsResponse
+	Related                    RelatedResponse
+	UnsupervisedClassification UnsupervisedClassifyResponse
+
+	// TaxonomyClassifications are the results of classify by taxonomy, by taxonomy.
+	TaxonomyClassifications map[Taxonomy]ClassifyByTaxonomyResponse
+
+	// Errors are the errors of the endpoints that failed, by endpoint.
+	Errors map[Endpoint]error
+
+	// Responses are the metadata of the calls sent, by endpoint.
+	// The endpoints called through /combined share the metadata of that call.
+	Responses map[Endpoint]*Response
+}
+
+// Err returns the errors of the endpoints that failed joined together,
+// or nil if all of them succeeded.
+func (r *AnalyzeResponse) Err() error {
+	endpoints := make([]Endpoint, 0, len(r.Errors))
+	for e := range r.Errors {
+		endpoints = append(endpoints, e)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i] < endpoints[j] })
+
+	errs := make([]error, len(endpoints))
+	for i, e := range endpoints {
+		errs[i] = fmt.Errorf("%s: %w", e, r.Errors[e])
+	}
+	return errors.Join(errs...)
+}
+
+// Analyze calls any mix of endpoints on the document defined by the given params information.
+// Endpoints supported by /combined are called with a single call to /combined,
+// and the other ones concurrently. A failed endpoint does not fail the whole call:
+// its error is recorded in the Errors of the response.
+func (c *Client) Analyze(params *AnalyzeParams) (*AnalyzeResponse, error) {
+	return c.AnalyzeContext(context.Background(), params)
+}
+
+// AnalyzeContext is like Analyze but uses ctx to carry cancellation and deadline
+// through the underlying API calls. opts apply to every call, except CaptureResponse
+// which is ignored: the metadata of each call is in the Responses of the response.
+func (c *Client) AnalyzeContext(ctx context.Context, params *AnalyzeParams, opts ...CallOption) (*AnalyzeResponse, error) {
+	input := inputOf(params.Input, params.Text, "", params.URL)
+	if err := input.checkSet(); err != nil {
+		return nil, err
+	}
+	if len(params.Endpoints) == 0 {
+		return nil, errors.New("you must provide at least one endpoint")
+	}
+
+	var combined, others []Endpoint
+	seen := map[Endpoint]bool{}
+	for _, e := range params.Endpoints {
+		switch {
+		case seen[e]:
+		case contains(combinedEndpoints, string(e)):
+			combined = append(combined, e)
+		case e.Valid():
+			others = append(others, e)
+		default:
+			return nil, fmt.Errorf("invalid endpoint %q, accepted values are %s", e, join(endpoints))
+		}
+		seen[e] = true
+	}
+	if err := validate("language", string(params.Language), languages); err != nil {
+		return nil, err
+	}
+	// /combined requires at least two endpoints.
+	if len(combined) == 1 {
+		others = append(combined, others...)
+		combined = nil
+	}
+
+	// The input is sent with every call, so it is read once beforehand.
+	input, err := input.buffered()
+	if err != nil {
+		return nil, err
+	}
+	p := *params
+	p.Input = input
+
+	maxConcurrency := params.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultMaxConcurrency
+	}
+
+	r := &AnalyzeResponse{Errors: map[Endpoint]error{}, Responses: map[Endpoint]*Response{}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrency)
+
+	// Each call captures its own metadata, overriding any CaptureResponse of opts,
+	// which would be written concurrently by every call.
+	run := func(endpoints []Endpoint, fn func(opts []CallOption) (func(), error)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var meta *Response
+			var set func()
+			var err error
+			select {
+			case sem <- struct{}{}:
+				meta = &Response{}
+				set, err = fn(append(opts[:len(opts):len(opts)], CaptureResponse(meta)))
+				<-sem
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if meta != nil {
+				for _, e := range endpoints {
+					r.Responses[e] = meta
+				}
+			}
+			if err != nil {
+				for _, e := range endpoints {
+					r.Errors[e] = err
+				}
+				return
+			}
+			set()
+		}()
+	}
+
+	if len(combined) > 0 {
+		run(combined, func(opts []CallOption) (func(), error) {
+			res, err := c.CombinedContext(ctx, &CombinedParams{Input: input, Endpoints: combined}, opts...)
+			if err != nil {
+				return nil, err
+			}
+			return func() {
+				r.Text = res.Text
+				for _, e := range combined {
+					if err := res.EndpointErr(e); err != nil {
+						r.Errors[e] = err
+						continue
+					}
+					r.setCombined(e, res)
+				}
+			}, nil
+		})
+	}
+	for _, e := range others {
+		e := e
+		run([]Endpoint{e}, func(opts []CallOption) (func(), error) {
+			return c.analyzeOne(ctx, r, e, &p, opts)
+		})
+	}
+
+	wg.Wait()
+	return r, nil
+}
+
+// setCombined copies the result of endpoint from res.
+func (r *AnalyzeResponse) setCombined(endpoint Endpoint, res *CombinedResponse) {
+	switch endpoint {
+	case EndpointExtract:
+		r.Article = res.Article
+	case EndpointSummarize:
+		r.Summary = res.Summary
+	case EndpointConcepts:
+		r.Concepts = res.Concepts
+	case EndpointEntities:
+		r.Entities = res.Entities
+	case EndpointHashtags:
+		r.Hashtags = res.Hashtags
+	case EndpointLanguage:
+		r.Language = res.Language
+	case EndpointSentiment:
+		r.Sentiment = res.Sentiment
+	case EndpointClassify:
+		r.Classifications = res.Classifications
+	}
+}
+
+// analyzeOne calls endpoint on its own, and returns a function storing its result in r.
+func (c *Client) analyzeOne(ctx context.Context, r *AnalyzeResponse, endpoint Endpoint, p *AnalyzeParams, opts []CallOption) (func(), error) {
+	switch endpoint {
+	case EndpointExtract:
+		res, err := c.ExtractContext(ctx, &ExtractParams{Input: p.Input, Language: p.Language}, opts...)
+		return func() { r.Article = *res }, err
+	case EndpointSummarize:
+		res, err := c.SummarizeContext(ctx, &SummarizeParams{Input: p.Input, Title: p.Title}, opts...)
+		return func() { r.Summary = *res }, err
+	case EndpointConcepts:
+		res, err := c.ConceptsContext(ctx, &ConceptsParams{Input: p.Input, Language: p.Language}, opts...)
+		return func() { r.Concepts = *res }, err
+	case EndpointEntities:
+		res, err := c.EntitiesContext(ctx, &EntitiesParams{Input: p.Input}, opts...)
+		return func() { r.Entities = *res }, err
+	case EndpointHashtags:
+		res, err := c.HashtagsContext(ctx, &HashtagsParams{Input: p.Input, Language: p.Language}, opts...)
+		return func() { r.Hashtags = *res }, err
+	case EndpointLanguage:
+		res, err := c.LanguageContext(ctx, &LanguageParams{Input: p.Input}, opts...)
+		return func() { r.Language = *res }, err
+	case EndpointSentiment:
+		res, err := c.SentimentContext(ctx, &SentimentParams{Input: p.Input}, opts...)
+		return func() { r.Sentiment = *res }, err
+	case EndpointClassify:
+		res, err := c.ClassifyContext(ctx, &ClassifyParams{Input: p.Input, Language: p.Language}, opts...)
+		return func() { r.Classifications = *res }, err
+	case EndpointMicroformats:
+		res, err := c.MicroformatsContext(ctx, &MicroformatsParams{Input: p.Input}, opts...)
+		return func() { r.Microformats = *res }, err
+	case EndpointImageTags:
+		res, err := c.ImageTagsContext(ctx, &ImageTagsParams{Input: p.Input}, opts...)
+		return func() { r.ImageTags = *res }, err
+	case EndpointRelated:
+		res, err := c.RelatedContext(ctx, &RelatedParams{Phrase: p.Phrase, Count: p.RelatedCount}, opts...)
+		return func() { r.Related = *res }, err
+	case EndpointUnsupervisedClassify:
+		res, err := c.UnsupervisedClassifyContext(ctx, &UnsupervisedClassifyParams{
+			Input:            p.Input,
+			Classes:          p.Classes,
+			NumberOfConcepts: p.NumberOfConcepts,
+		}, opts...)
+		return func() { r.UnsupervisedClassification = *res }, err
+	}
+
+	taxonomy := Taxonomy(strings.TrimPrefix(string(endpoint), "classify/"))
+	res, err := c.ClassifyByTaxonomyContext(ctx, &ClassifyByTaxonomyParams{
+		Input:    p.Input,
+		Language: p.Language,
+		Taxonomy: taxonomy,
+	}, opts...)
+	return func() {
+		if r.TaxonomyClassifications == nil {
+			r.TaxonomyClassifications = map[Taxonomy]ClassifyByTaxonomyResponse{}
+		}
+		r.TaxonomyClassifications[taxonomy] = *res
+	}, err
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAnalyze(t *testing.T) {
+	var inFlight, maxInFlight, requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		switch r.URL.Path {
+		case "/combined":
+			fmt.Fprint(w, `{"text": "text", "results": [
+				{"endpoint": "sentiment", "result": {"polarity": "negative"}},
+				{"endpoint": "entities", "result": {"entities": {"location": ["Dublin"]}}}
+			]}`)
+		case "/microformats":
+			fmt.Fprint(w, `{"hCards": [{"fullName": "John Doe"}]}`)
+		case "/image-tags":
+			fmt.Fprint(w, `{"tags": [{"tag": "dog", "confidence": 0.9}]}`)
+		case "/classify/iab-qag", "/classify/iptc-subjectcode":
+			fmt.Fprint(w, `{"taxonomy": "`+r.URL.Path[len("/classify/"):]+`"}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "bad request"}`)
+		}
+	}))
+	defer server.Close()
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL))
+
+	r, err := c.Analyze(&AnalyzeParams{
+		URL: "http://example.com",
+		Endpoints: []Endpoint{
+			"sentiment", "entities", "microformats", "image-tags",
+			"classify/iab-qag", "classify/iptc-subjectcode", "classify/unsupervised",
+		},
+		Classes:        []string{"a", "b"},
+		MaxConcurrency: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Sentiment.Polarity != "negative" || r.Entities.Entities["location"][0] != "Dublin" {
+		t.Errorf("unexpected combined results %+v", r)
+	}
+	if len(r.Microformats.HCards) != 1 || len(r.ImageTags.Tags) != 1 {
+		t.Errorf("unexpected results %+v", r)
+	}
+	if r.TaxonomyClassifications["iab-qag"].Taxonomy != "iab-qag" || r.TaxonomyClassifications["iptc-subjectcode"].Taxonomy != "iptc-subjectcode" {
+		t.Errorf("unexpected taxonomy classifications %+v", r.TaxonomyClassifications)
+	}
+	if len(r.Errors) != 1 || !errors.Is(r.Errors["classify/unsupervised"], ErrInvalidInput) {
+		t.Errorf("unexpected errors %v", r.Errors)
+	}
+	if err := r.Err(); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if requests != 6 || maxInFlight > 2 {
+		t.Errorf("unexpected %d requests, %d at most at once", requests, maxInFlight)
+	}
+}
+
+func TestAnalyzeSingleCombinedEndpoint(t *testing.T) {
+	server, paths := newDocumentServer()
+	defer server.Close()
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL))
+
+	r, err := c.Analyze(&AnalyzeParams{Text: "text", Endpoints: []Endpoint{"sentiment", "sentiment"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Sentiment.Polarity != "positive" || r.Err() != nil {
+		t.Errorf("unexpected result %+v", r)
+	}
+	if p := paths(); len(p) != 1 || p[0] != "/sentiment?" {
+		t.Errorf("unexpected calls %v", p)
+	}
+}
+
+func TestAnalyzeCaptureResponse(t *testing.T) {
+	// The calls overlap, so that the race detector catches a response
+	// written by several of them.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprint(w, "{}")
+	}))
+	defer server.Close()
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL))
+
+	var captured Response
+	r, err := c.AnalyzeContext(context.Background(), &AnalyzeParams{
+		Text:    "text",
+		Classes: []string{"a", "b"},
+		Phrase:  "phrase",
+		Endpoints: []Endpoint{
+			EndpointSentiment, EndpointEntities, EndpointRelated,
+			EndpointUnsupervisedClassify, EndpointClassifyIABQAG,
+		},
+	}, CaptureResponse(&captured))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if captured.StatusCode != 0 {
+		t.Errorf("response captured by Analyze: %+v", captured)
+	}
+	if len(r.Responses) != 5 || r.Responses[EndpointSentiment] != r.Responses[EndpointEntities] {
+		t.Errorf("unexpected responses %v", r.Responses)
+	}
+	for e, res := range r.Responses {
+		if res.StatusCode != http.StatusOK || res.Attempts != 1 {
+			t.Errorf("unexpected response of %s: %+v", e, res)
+		}
+	}
+}
+
+func TestAnalyzeInvalidParams(t *testing.T) {
+	for _, params := range []*AnalyzeParams{
+		{Endpoints: []Endpoint{"sentiment"}},
+		{Text: "text"},
+		{Text: "text", Endpoints: []Endpoint{"unknown"}},
+		{Text: "text", Endpoints: []Endpoint{"classify/"}},
+		{Text: "text", Endpoints: []Endpoint{"sentiment"}, Language: "xx"},
+	} {
+		if _, err := client.Analyze(params); err == nil {
+			t.Errorf("expected an error for %+v", params)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import "context"
+
+// A SentimentAnalyzer detects the sentiment of documents, see Client.Sentiment.
+type SentimentAnalyzer interface {
+	SentimentContext(ctx context.Context, params *SentimentParams, opts ...CallOption) (*SentimentResponse, error)
+}
+
+// An EntitiesAnalyzer extracts the entities of documents, see Client.Entities.
+type EntitiesAnalyzer interface {
+	EntitiesContext(ctx context.Context, params *EntitiesParams, opts ...CallOption) (*EntitiesResponse, error)
+}
+
+// A ConceptsAnalyzer extracts the concepts of documents, see Client.Concepts.
+type ConceptsAnalyzer interface {
+	ConceptsContext(ctx context.Context, params *ConceptsParams, opts ...CallOption) (*ConceptsResponse, error)
+}
+
+// A ClassifyAnalyzer classifies documents, see Client.Classify.
+type ClassifyAnalyzer interface {
+	ClassifyContext(ctx context.Context, params *ClassifyParams, opts ...CallOption) (*ClassifyResponse, error)
+}
+
+// A ClassifyByTaxonomyAnalyzer classifies documents according to a taxonomy, see Client.ClassifyByTaxonomy.
+type ClassifyByTaxonomyAnalyzer interface {
+	ClassifyByTaxonomyContext(ctx context.Context, params *ClassifyByTaxonomyParams, opts ...CallOption) (*ClassifyByTaxonomyResponse, error)
+}
+
+// An UnsupervisedClassifyAnalyzer classifies documents into given classes, see Client.UnsupervisedClassify.
+type UnsupervisedClassifyAnalyzer interface {
+	UnsupervisedClassifyContext(ctx context.Context, params *UnsupervisedClassifyParams, opts ...CallOption) (*UnsupervisedClassifyResponse, error)
+}
+
+// A HashtagsAnalyzer suggests hashtags for documents, see Client.Hashtags.
+type HashtagsAnalyzer interface {
+	HashtagsContext(ctx context.Context, params *HashtagsParams, opts ...CallOption) (*HashtagsResponse, error)
+}
+
+// A LanguageAnalyzer detects the language of documents, see Client.Language.
+type LanguageAnalyzer interface {
+	LanguageContext(ctx context.Context, params *LanguageParams, opts ...CallOption) (*LanguageResponse, error)
+}
+
+// A SummarizeAnalyzer summarizes documents, see Client.Summarize.
+type SummarizeAnalyzer interface {
+	SummarizeContext(ctx context.Context, params *SummarizeParams, opts ...CallOption) (*SummarizeResponse, error)
+}
+
+// An ExtractAnalyzer extracts the article of web pages, see Client.Extract.
+type ExtractAnalyzer interface {
+	ExtractContext(ctx context.Context, params *ExtractParams, opts ...CallOption) (*ExtractResponse, error)
+}
+
+// A RelatedAnalyzer returns the phrases related to a phrase, see Client.Related.
+type RelatedAnalyzer interface {
+	RelatedContext(ctx context.Context, params *RelatedParams, opts ...CallOption) (*RelatedResponse, error)
+}
+
+// An ImageTagsAnalyzer tags images, see Client.ImageTags.
+type ImageTagsAnalyzer interface {
+	ImageTagsContext(ctx context.Context, params *ImageTagsParams, opts ...CallOption) (*ImageTagsResponse, error)
+}
+
+// A MicroformatsAnalyzer extracts the microformats of web pages, see Client.Microformats.
+type MicroformatsAnalyzer interface {
+	MicroformatsContext(ctx context.Context, params *MicroformatsParams, opts ...CallOption) (*MicroformatsResponse, error)
+}
+
+// A CombinedAnalyzer runs several analyses of documents in a single call, see Client.Combined.
+type CombinedAnalyzer interface {
+	CombinedContext(ctx context.Context, params *CombinedParams, opts ...CallOption) (*CombinedResponse, error)
+}
+
+// A TextAnalyzer provides every analysis of the Text API.
+// *Client is a TextAnalyzer. Code that depends on a TextAnalyzer,
+// or only on the analyses it needs, can be tested with the in-memory
+// fake of the textapitest package instead of a Client.
+type TextAnalyzer interface {
+	SentimentAnalyzer
+	EntitiesAnalyzer
+	ConceptsAnalyzer
+	ClassifyAnalyzer
+	ClassifyByTaxonomyAnalyzer
+	UnsupervisedClassifyAnalyzer
+	HashtagsAnalyzer
+	LanguageAnalyzer
+	SummarizeAnalyzer
+	ExtractAnalyzer
+	RelatedAnalyzer
+	ImageTagsAnalyzer
+	MicroformatsAnalyzer
+	CombinedAnalyzer
+}
+
+var _ TextAnalyzer = (*Client)(nil)
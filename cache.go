@@ -0,0 +1,317 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A Cache stores the raw bodies of successful API responses.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key, if it has not expired.
+	Get(key string) ([]byte, bool)
+
+	// Set stores value under key for ttl. If ttl is zero, value does not expire.
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// A StaleCache is a Cache that can also return expired entries, see WithStaleIfError.
+type StaleCache interface {
+	Cache
+
+	// GetStale returns the value stored under key, whether it has expired or not,
+	// and the time it expires at, or the zero time if it does not expire.
+	GetStale(key string) (value []byte, expires time.Time, ok bool)
+}
+
+// WithCache makes the client answer calls from cache when it can, and store
+// the results of successful calls in it for ttl. Error responses are never cached.
+//
+// Results are cached per endpoint and form values, so identical calls share a
+// cache entry whatever the order of their form values.
+// WithCacheTTL overrides ttl for a given endpoint, and BypassCache
+// forces a single call to be sent to the API.
+func WithCache(cache Cache, ttl time.Duration) Option {
+	return func(c *Client) error {
+		if cache == nil {
+			return errors.New("cache must not be nil")
+		}
+		if ttl < 0 {
+			return errors.New("cache ttl must not be negative")
+		}
+		c.cache = cache
+		c.cacheTTL = ttl
+		c.keepBodies = true
+		return nil
+	}
+}
+
+// WithCacheTTL sets how long the results of endpoint are cached,
+// e.g. /sentiment or /classify/iab-qag, instead of the ttl given to WithCache.
+// If ttl is zero, the results of endpoint are not cached.
+func WithCacheTTL(endpoint string, ttl time.Duration) Option {
+	return func(c *Client) error {
+		if ttl < 0 {
+			return errors.New("cache ttl must not be negative")
+		}
+		if !strings.HasPrefix(endpoint, "/") {
+			endpoint = "/" + endpoint
+		}
+		if c.cacheTTLs == nil {
+			c.cacheTTLs = map[string]time.Duration{}
+		}
+		c.cacheTTLs[endpoint] = ttl
+		return nil
+	}
+}
+
+// WithStaleIfError makes the client return the cached result of a call even if it
+// has expired, rather than an error, when the API fails with a 5xx status,
+// rejects the call because of the rate limit, or does not answer in time.
+// Results that expired more than maxStale ago are not returned,
+// unless maxStale is zero. Response.Stale is set for such results.
+//
+// The cache given to WithCache must implement StaleCache, as MemoryCache and FileCache do.
+func WithStaleIfError(maxStale time.Duration) Option {
+	return func(c *Client) error {
+		if maxStale < 0 {
+			return errors.New("max stale must not be negative")
+		}
+		c.staleIfError = true
+		c.maxStale = maxStale
+		return nil
+	}
+}
+
+// BypassCache makes the call skip the cache lookup. The result of the call,
+// if successful, still replaces the cached one.
+func BypassCache() CallOption {
+	return func(o *callOptions) {
+		o.bypassCache = true
+	}
+}
+
+// CacheStats counts the lookups of a client in its cache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+
+	// Stale is the number of failed calls answered with an expired result.
+	Stale uint64
+}
+
+type cacheStats struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	stale  atomic.Uint64
+}
+
+// CacheStats returns the number of calls answered from the cache, of those
+// looked up in it without success, and of those answered with a stale result,
+// since the client was created.
+func (c *Client) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:   c.cacheStats.hits.Load(),
+		Misses: c.cacheStats.misses.Load(),
+		Stale:  c.cacheStats.stale.Load(),
+	}
+}
+
+// cached returns an Invoker that answers calls from the cache of the client,
+// and calls next otherwise.
+func (c *Client) cached(next Invoker) Invoker {
+	return func(ctx context.Context, call *Call) error {
+		ttl, ok := c.cacheTTLs[call.Endpoint]
+		if !ok {
+			ttl = c.cacheTTL
+		}
+		if call.Result == nil || (ok && ttl == 0) {
+			return next(ctx, call)
+		}
+
+		key := cacheKey(call.Endpoint, call.Form)
+		if !call.options.bypassCache {
+			if data, ok := c.cache.Get(key); ok && json.Unmarshal(data, call.Result) == nil {
+				c.cacheStats.hits.Add(1)
+				*call.Response = Response{Cached: true}
+				return nil
+			}
+			c.cacheStats.misses.Add(1)
+		}
+
+		if err := next(ctx, call); err != nil {
+			if c.staleIfError && staleError(err) && c.getStale(key, call.Result) {
+				c.cacheStats.stale.Add(1)
+				call.Response.Cached = true
+				call.Response.Stale = true
+				return nil
+			}
+			return err
+		}
+		if len(call.body) > 0 {
+			c.cache.Set(key, call.body, ttl)
+		}
+		return nil
+	}
+}
+
+// getStale decodes the result cached under key into v, even if it has expired,
+// unless it expired more than maxStale ago.
+func (c *Client) getStale(key string, v interface{}) bool {
+	data, expires, ok := c.cache.(StaleCache).GetStale(key)
+	if !ok {
+		return false
+	}
+	if c.maxStale > 0 && !expires.IsZero() && time.Since(expires) > c.maxStale {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// staleError returns whether a call failing with err may be answered with a stale result.
+func staleError(err error) bool {
+	if errors.Is(err, ErrServer) || errors.Is(err, ErrRateLimited) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// cacheKey returns the cache key of a call to endpoint with form,
+// with the form values sorted.
+func cacheKey(endpoint string, form url.Values) string {
+	keys := make([]string, 0, len(form))
+	for k := range form {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(endpoint)
+	b.WriteByte('?')
+	for _, k := range keys {
+		values := append([]string(nil), form[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			if b.Len() > len(endpoint)+1 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(k))
+			b.WriteByte('=')
+			b.WriteString(url.QueryEscape(v))
+		}
+	}
+	return b.String()
+}
+
+// A MemoryCache is an in-memory Cache holding up to a maximum number of entries.
+// When it is full, the least recently used entry is evicted.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns a MemoryCache holding up to maxEntries entries.
+// If maxEntries is zero, the number of entries is not limited
+// and expired entries are only removed when they are replaced.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*memoryEntry)
+	if !entry.expires.IsZero() && !time.Now().Before(entry.expires) {
+		return nil, false
+	}
+	m.lru.MoveToFront(e)
+	return entry.value, true
+}
+
+// GetStale implements StaleCache. Expired entries are kept
+// until they are evicted to make room for new ones.
+func (m *MemoryCache) GetStale(key string) ([]byte, time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	entry := e.Value.(*memoryEntry)
+	return entry.value, entry.expires, true
+}
+
+// Set implements Cache.
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &memoryEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+
+	if e, ok := m.entries[key]; ok {
+		e.Value = entry
+		m.lru.MoveToFront(e)
+		return
+	}
+	m.entries[key] = m.lru.PushFront(entry)
+	if m.maxEntries > 0 && m.lru.Len() > m.maxEntries {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+// Len returns the number of entries in the cache, including expired ones not yet evicted.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len()
+}
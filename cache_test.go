@@ -0,0 +1,224 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	server, requests := newFlakyServer(0, 0)
+	defer server.Close()
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithCache(NewMemoryCache(10), time.Minute))
+	for i := 0; i < 3; i++ {
+		var r Response
+		sentiment, err := c.SentimentContext(context.Background(), &SentimentParams{Text: "text"}, CaptureResponse(&r))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sentiment.Polarity != "positive" {
+			t.Error("invalid response")
+		}
+		if r.Cached != (i > 0) {
+			t.Errorf("call %d: unexpected Cached %v", i, r.Cached)
+		}
+	}
+	if *requests != 1 {
+		t.Errorf("expected 1 request, got %d", *requests)
+	}
+	if stats := c.CacheStats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	if _, err := c.SentimentContext(context.Background(), &SentimentParams{Text: "text"}, BypassCache()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Sentiment(&SentimentParams{Text: "other text"}); err != nil {
+		t.Fatal(err)
+	}
+	if *requests != 3 {
+		t.Errorf("expected 3 requests, got %d", *requests)
+	}
+}
+
+func TestCacheErrorsNotCached(t *testing.T) {
+	server, requests := newFlakyServer(1, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithCache(NewMemoryCache(10), time.Minute))
+	if _, err := c.Sentiment(&SentimentParams{Text: "text"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := c.Sentiment(&SentimentParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	if *requests != 2 {
+		t.Errorf("expected 2 requests, got %d", *requests)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	server, requests := newFlakyServer(0, 0)
+	defer server.Close()
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL),
+		WithCache(NewMemoryCache(10), time.Minute),
+		WithCacheTTL("sentiment", 0))
+	c.Sentiment(&SentimentParams{Text: "text"})
+	c.Sentiment(&SentimentParams{Text: "text"})
+	if *requests != 2 {
+		t.Errorf("expected 2 requests, got %d", *requests)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	a := cacheKey("/combined", url.Values{"text": {"t"}, "endpoint": {"sentiment", "entities"}})
+	b := cacheKey("/combined", url.Values{"endpoint": {"entities", "sentiment"}, "text": {"t"}})
+	if a != b {
+		t.Errorf("keys differ: %q and %q", a, b)
+	}
+	if a != "/combined?endpoint=entities&endpoint=sentiment&text=t" {
+		t.Errorf("unexpected key %q", a)
+	}
+	if cacheKey("/concepts", url.Values{"text": {"t"}}) == cacheKey("/entities", url.Values{"text": {"t"}}) {
+		t.Error("keys of different endpoints are equal")
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	m := NewMemoryCache(2)
+	m.Set("a", []byte("1"), 0)
+	m.Set("b", []byte("2"), 0)
+	m.Get("a")
+	m.Set("c", []byte("3"), 0)
+	if _, ok := m.Get("b"); ok {
+		t.Error("least recently used entry not evicted")
+	}
+	if v, ok := m.Get("a"); !ok || string(v) != "1" {
+		t.Errorf("unexpected entry %q", v)
+	}
+
+	m.Set("d", []byte("4"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, ok := m.Get("d"); ok {
+		t.Error("expired entry returned")
+	}
+	if _, _, ok := m.GetStale("d"); !ok {
+		t.Error("expired entry evicted")
+	}
+	if m.Len() != 2 {
+		t.Errorf("unexpected length %d", m.Len())
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	dir := t.TempDir()
+	f, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Set("a", []byte(`{"polarity": "positive"}`), 0)
+	f.Set("b", []byte("2"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	f, _ = NewFileCache(dir)
+	if v, ok := f.Get("a"); !ok || string(v) != `{"polarity": "positive"}` {
+		t.Errorf("unexpected entry %q", v)
+	}
+	if _, ok := f.Get("b"); ok {
+		t.Error("expired entry returned")
+	}
+	if _, ok := f.Get("c"); ok {
+		t.Error("missing entry returned")
+	}
+
+	if err := f.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := f.read(f.path("b")); ok {
+		t.Error("expired entry not pruned")
+	}
+	if _, ok := f.Get("a"); !ok {
+		t.Error("entry pruned")
+	}
+}
+
+func TestCacheStaleIfError(t *testing.T) {
+	var fail bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprintln(w, `{"polarity": "positive"}`)
+	}))
+	defer server.Close()
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL),
+		WithCache(NewMemoryCache(10), time.Nanosecond), WithStaleIfError(0))
+	if _, err := c.Sentiment(&SentimentParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+
+	fail = true
+	var r Response
+	sentiment, err := c.SentimentContext(context.Background(), &SentimentParams{Text: "text"}, CaptureResponse(&r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sentiment.Polarity != "positive" || !r.Stale || !r.Cached || r.StatusCode != http.StatusBadGateway {
+		t.Errorf("unexpected stale result %+v, %+v", sentiment, r)
+	}
+	if stats := c.CacheStats(); stats.Stale != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	if _, err := c.Sentiment(&SentimentParams{Text: "other text"}); !errors.Is(err, ErrServer) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestCacheStaleIfErrorMaxStale(t *testing.T) {
+	m := NewMemoryCache(10)
+	m.Set(cacheKey("/sentiment", url.Values{"text": {"text"}}), []byte(`{"polarity": "positive"}`), time.Nanosecond)
+	time.Sleep(10 * time.Millisecond)
+
+	server, _ := newFlakyServer(1, http.StatusTooManyRequests)
+	defer server.Close()
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithCache(m, time.Minute), WithStaleIfError(time.Millisecond))
+	if _, err := c.Sentiment(&SentimentParams{Text: "text"}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type plainCache struct{ Cache }
+
+func TestCacheStaleIfErrorRequiresStaleCache(t *testing.T) {
+	_, err := NewClient(auth, false, WithCache(plainCache{NewMemoryCache(1)}, time.Minute), WithStaleIfError(0))
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
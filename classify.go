@@ -17,20 +17,19 @@ limitations under the License.
 package textapi
 
 import (
-	"errors"
-	"net/url"
-	"strconv"
+	"context"
 )
 
 // ClassifyParams is the set of parameters that defines a document whose classification needs to be calculated.
 type ClassifyParams struct {
-	// Either URL or Text is required.
-	URL  string
-	Text string
+	// Either Input, URL or Text is required, see Input.
+	Input Input
+	URL   string
+	Text  string
 
-	// Valid languages are en, de, fr, es, it, pt and auto.
+	// Valid languages are en, de, fr, es, it, pt and auto, see Language.
 	// Default is en.
-	Language string
+	Language Language `form:"language,omitempty,enum=language"`
 }
 
 // A Category is the JSON description of a classification category.
@@ -50,15 +49,16 @@ type ClassifyResponse struct {
 
 // UnsupervisedClassifyParams is the set of parameters that defines a document whose needs to be classified.
 type UnsupervisedClassifyParams struct {
-	// Either URL or Text is required.
-	URL  string
-	Text string
+	// Either Input, URL or Text is required, see Input.
+	Input Input
+	URL   string
+	Text  string
 
-	// List of classes to classify into
-	Classes []string
+	// List of classes to classify into, at least two are required.
+	Classes []string `form:"class,min=2,label=classes"`
 
 	// Number of concepts used to measure the semantic similarity between two words.
-	NumberOfConcepts int
+	NumberOfConcepts int `form:"number_of_concepts,omitempty,min=1"`
 }
 
 // An UnsupervisedClassifyClass is the JSON description of a class.
@@ -75,16 +75,17 @@ type UnsupervisedClassifyResponse struct {
 
 // A ClassifyByTaxonomyParams is the set of parameters that defines a document whose needs to be classified according to a taxonomy.
 type ClassifyByTaxonomyParams struct {
-	// Either URL or Text is required.
-	URL  string
-	Text string
+	// Either Input, URL or Text is required, see Input.
+	Input Input
+	URL   string
+	Text  string
 
-	// Valid languages are en, de, fr, es, it, pt and auto.
+	// Valid languages are en, de, fr, es, it, pt and auto, see Language.
 	// Default is en.
-	Language string
+	Language Language `form:"language,omitempty,enum=language"`
 
-	// Valid taxonomies are iab-qag and iptc-subjectcode.
-	Taxonomy string
+	// Valid taxonomies are iab-qag and iptc-subjectcode, see Taxonomy.
+	Taxonomy Taxonomy `form:"taxonomy,path,required,enum=taxonomy"`
 }
 
 // A ClassifyByTaxonomyResponse is the JSON description of classification by taxonomy response.
@@ -106,22 +107,19 @@ type ClassifyByTaxonomyResponse struct {
 
 // Classify classifies the document defined by the given params information.
 func (c *Client) Classify(params *ClassifyParams) (*ClassifyResponse, error) {
-	body := &url.Values{}
-
-	if len(params.Text) > 0 {
-		body.Add("text", params.Text)
-	} else if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else {
-		return nil, errors.New("you must either provide url or text")
-	}
+	return c.ClassifyContext(context.Background(), params)
+}
 
-	if len(params.Language) > 0 {
-		body.Add("language", params.Language)
+// ClassifyContext is like Classify but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) ClassifyContext(ctx context.Context, params *ClassifyParams, opts ...CallOption) (*ClassifyResponse, error) {
+	body, err := c.encode(EndpointClassify, inputOf(params.Input, params.Text, "", params.URL), params)
+	if err != nil {
+		return nil, err
 	}
 
 	classification := &ClassifyResponse{}
-	err := c.call("/classify", body, classification)
+	err = c.call(ctx, "/classify", body, classification, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -131,29 +129,19 @@ func (c *Client) Classify(params *ClassifyParams) (*ClassifyResponse, error) {
 
 // UnsupervisedClassify picks the most semantically relevant class label or tag for the document defined by the given params information.
 func (c *Client) UnsupervisedClassify(params *UnsupervisedClassifyParams) (*UnsupervisedClassifyResponse, error) {
-	body := &url.Values{}
-
-	if len(params.Text) > 0 {
-		body.Add("text", params.Text)
-	} else if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else {
-		return nil, errors.New("you must either provide url or text")
-	}
-
-	if params.NumberOfConcepts > 0 {
-		body.Add("number_of_concepts", strconv.Itoa(params.NumberOfConcepts))
-	}
+	return c.UnsupervisedClassifyContext(context.Background(), params)
+}
 
-	if len(params.Classes) < 2 {
-		return nil, errors.New("you must provide at least two classes")
-	}
-	for _, c := range params.Classes {
-		body.Add("class", c)
+// UnsupervisedClassifyContext is like UnsupervisedClassify but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) UnsupervisedClassifyContext(ctx context.Context, params *UnsupervisedClassifyParams, opts ...CallOption) (*UnsupervisedClassifyResponse, error) {
+	body, err := c.encode(EndpointUnsupervisedClassify, inputOf(params.Input, params.Text, "", params.URL), params)
+	if err != nil {
+		return nil, err
 	}
 
 	classes := &UnsupervisedClassifyResponse{}
-	err := c.call("/classify/unsupervised", body, classes)
+	err = c.call(ctx, "/classify/unsupervised", body, classes, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -163,27 +151,19 @@ func (c *Client) UnsupervisedClassify(params *UnsupervisedClassifyParams) (*Unsu
 
 // ClassifyByTaxonomy classifies the document defined by the given params information according to the specified taxonomy.
 func (c *Client) ClassifyByTaxonomy(params *ClassifyByTaxonomyParams) (*ClassifyByTaxonomyResponse, error) {
+	return c.ClassifyByTaxonomyContext(context.Background(), params)
+}
 
-	body := &url.Values{}
-
-	if len(params.Text) > 0 {
-		body.Add("text", params.Text)
-	} else if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else {
-		return nil, errors.New("you must either provide url or text")
-	}
-
-	if len(params.Taxonomy) == 0 {
-		return nil, errors.New("you must specify the taxonomy")
-	}
-
-	if len(params.Language) > 0 {
-		body.Add("language", params.Language)
+// ClassifyByTaxonomyContext is like ClassifyByTaxonomy but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) ClassifyByTaxonomyContext(ctx context.Context, params *ClassifyByTaxonomyParams, opts ...CallOption) (*ClassifyByTaxonomyResponse, error) {
+	body, err := c.encode(Endpoint("classify/"+string(params.Taxonomy)), inputOf(params.Input, params.Text, "", params.URL), params)
+	if err != nil {
+		return nil, err
 	}
 
 	classifications := &ClassifyByTaxonomyResponse{}
-	err := c.call("/classify/"+params.Taxonomy, body, classifications)
+	err = c.call(ctx, "/classify/"+string(params.Taxonomy), body, classifications, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -17,20 +17,25 @@ limitations under the License.
 package textapi
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"net/url"
+	"fmt"
 )
 
 type CombinedParams struct {
-	URL       string
-	Text      string
-	Endpoints []string
+	// Either Input, URL or Text is required, see Input.
+	Input Input
+	URL   string
+	Text  string
+
+	// Endpoints are at least two distinct endpoints supported by /combined:
+	// extract, summarize, concepts, entities, hashtags, language, sentiment and classify.
+	Endpoints []Endpoint `form:"endpoint,unique,min=2,label=endpoints,enum=combined_endpoint"`
 }
 
 type endpointResult struct {
-	Endpoint string      `json:"endpoint"`
-	Result   interface{} `json:"result"`
+	Endpoint Endpoint        `json:"endpoint"`
+	Result   json.RawMessage `json:"result"`
 }
 
 type combinedRawResponse struct {
@@ -38,6 +43,9 @@ type combinedRawResponse struct {
 	Results []endpointResult `json:"results"`
 }
 
+// CombinedResponse is the JSON description of combined response.
+// An endpoint result that cannot be decoded does not fail the whole response:
+// its error is recorded in Errors and the other results are still decoded.
 type CombinedResponse struct {
 	Text            string
 	Article         ExtractResponse
@@ -48,6 +56,16 @@ type CombinedResponse struct {
 	Language        LanguageResponse
 	Sentiment       SentimentResponse
 	Classifications ClassifyResponse
+
+	// Endpoints is the list of endpoints returned, in order.
+	Endpoints []Endpoint
+
+	// Errors are the errors decoding endpoint results, by endpoint.
+	// They match ErrInvalidResponse.
+	Errors map[Endpoint]error
+
+	// Raw are the results of the endpoints CombinedResponse has no field for, by endpoint.
+	Raw map[Endpoint]json.RawMessage
 }
 
 func (c *CombinedResponse) UnmarshalJSON(data []byte) error {
@@ -58,56 +76,81 @@ func (c *CombinedResponse) UnmarshalJSON(data []byte) error {
 	}
 	c.Text = combinedRaw.Text
 	for _, r := range combinedRaw.Results {
-		o, err := json.Marshal(r.Result)
-		if err != nil {
-			return err
-		}
+		c.Endpoints = append(c.Endpoints, r.Endpoint)
+
+		var v interface{}
 		switch r.Endpoint {
-		case "extract":
-			err = json.Unmarshal(o, &c.Article)
-		case "language":
-			err = json.Unmarshal(o, &c.Language)
-		case "entities":
-			err = json.Unmarshal(o, &c.Entities)
-		case "concepts":
-			err = json.Unmarshal(o, &c.Concepts)
-		case "classify":
-			err = json.Unmarshal(o, &c.Classifications)
-		case "hashtags":
-			err = json.Unmarshal(o, &c.Hashtags)
-		case "sentiment":
-			err = json.Unmarshal(o, &c.Sentiment)
-		case "summarize":
-			err = json.Unmarshal(o, &c.Summary)
+		case EndpointExtract:
+			v = &c.Article
+		case EndpointLanguage:
+			v = &c.Language
+		case EndpointEntities:
+			v = &c.Entities
+		case EndpointConcepts:
+			v = &c.Concepts
+		case EndpointClassify:
+			v = &c.Classifications
+		case EndpointHashtags:
+			v = &c.Hashtags
+		case EndpointSentiment:
+			v = &c.Sentiment
+		case EndpointSummarize:
+			v = &c.Summary
+		default:
+			if c.Raw == nil {
+				c.Raw = map[Endpoint]json.RawMessage{}
+			}
+			c.Raw[r.Endpoint] = r.Result
+			continue
 		}
-		if err != nil {
-			return err
+
+		if err := json.Unmarshal(r.Result, v); err != nil {
+			if c.Errors == nil {
+				c.Errors = map[Endpoint]error{}
+			}
+			c.Errors[r.Endpoint] = fmt.Errorf("%w: %s: %w", ErrInvalidResponse, r.Endpoint, err)
 		}
 	}
 
 	return nil
 }
 
-func (c *Client) Combined(params *CombinedParams) (*CombinedResponse, error) {
-	body := &url.Values{}
-
-	if len(params.Text) > 0 {
-		body.Add("text", params.Text)
-	} else if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else {
-		return nil, errors.New("you must either provide url or text")
+// Returned returns whether the response has a result for endpoint, even one that could not be decoded.
+func (c *CombinedResponse) Returned(endpoint Endpoint) bool {
+	for _, e := range c.Endpoints {
+		if e == endpoint {
+			return true
+		}
 	}
+	return false
+}
 
-	if len(params.Endpoints) < 2 {
-		return nil, errors.New("you must provide at least two endpoints")
+// EndpointErr returns the error decoding the result of endpoint,
+// or an error matching ErrInvalidResponse if there is no result for endpoint.
+func (c *CombinedResponse) EndpointErr(endpoint Endpoint) error {
+	if err := c.Errors[endpoint]; err != nil {
+		return err
 	}
-	for _, p := range params.Endpoints {
-		body.Add("endpoint", p)
+	if !c.Returned(endpoint) {
+		return fmt.Errorf("%w: no result for %s", ErrInvalidResponse, endpoint)
+	}
+	return nil
+}
+
+func (c *Client) Combined(params *CombinedParams) (*CombinedResponse, error) {
+	return c.CombinedContext(context.Background(), params)
+}
+
+// CombinedContext is like Combined but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) CombinedContext(ctx context.Context, params *CombinedParams, opts ...CallOption) (*CombinedResponse, error) {
+	body, err := c.encode(Endpoint("combined"), inputOf(params.Input, params.Text, "", params.URL), params)
+	if err != nil {
+		return nil, err
 	}
 
 	response := &CombinedResponse{}
-	err := c.call("/combined", body, response)
+	err = c.call(ctx, "/combined", body, response, opts...)
 	if err != nil {
 		return nil, err
 	}
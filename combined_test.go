@@ -0,0 +1,75 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const partialCombinedResponse = `{"text": "text", "results": [
+	{"endpoint": "sentiment", "result": {"polarity": "positive"}},
+	{"endpoint": "entities", "result": {"entities": ["not", "a", "map"]}},
+	{"endpoint": "new-endpoint", "result": {"answer": 42}},
+	{"endpoint": "language", "result": {"lang": "en", "confidence": 0.99}}
+]}`
+
+func TestCombinedPartialResults(t *testing.T) {
+	var r CombinedResponse
+	if err := json.Unmarshal([]byte(partialCombinedResponse), &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Text != "text" || r.Sentiment.Polarity != "positive" || r.Language.Language != "en" {
+		t.Errorf("unexpected results %+v", r)
+	}
+	if len(r.Endpoints) != 4 || !r.Returned("new-endpoint") || r.Returned("concepts") {
+		t.Errorf("unexpected endpoints %v", r.Endpoints)
+	}
+	if len(r.Errors) != 1 || !errors.Is(r.Errors["entities"], ErrInvalidResponse) {
+		t.Errorf("unexpected errors %v", r.Errors)
+	}
+	if string(r.Raw["new-endpoint"]) != `{"answer": 42}` {
+		t.Errorf("unexpected raw results %v", r.Raw)
+	}
+
+	if err := r.EndpointErr("sentiment"); err != nil {
+		t.Error(err)
+	}
+	if err := r.EndpointErr("entities"); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := r.EndpointErr("concepts"); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestCombinedInvalidResponse(t *testing.T) {
+	var r CombinedResponse
+	if err := json.Unmarshal([]byte(`{"results": {}}`), &r); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func BenchmarkCombinedUnmarshal(b *testing.B) {
+	data := []byte(partialCombinedResponse)
+	for i := 0; i < b.N; i++ {
+		var r CombinedResponse
+		json.Unmarshal(data, &r)
+	}
+}
@@ -17,19 +17,19 @@ limitations under the License.
 package textapi
 
 import (
-	"errors"
-	"net/url"
+	"context"
 )
 
 // ConceptsParams is the set of parameters that defines a document whose concepts needs to be extracted.
 type ConceptsParams struct {
-	// Either URL or Text is required.
-	URL  string
-	Text string
+	// Either Input, URL or Text is required, see Input.
+	Input Input
+	URL   string
+	Text  string
 
-	// Valid languages are en, de, fr, es, it, pt and auto.
+	// Valid languages are en, de, fr, es, it, pt and auto, see Language.
 	// Default is en.
-	Language string
+	Language Language `form:"language,omitempty,enum=language"`
 }
 
 // A SurfaceForm is the JSON description of a concept's surface form.
@@ -55,22 +55,19 @@ type ConceptsResponse struct {
 
 // Concepts extracts concepts mentioned in the document defined by the given params information.
 func (c *Client) Concepts(params *ConceptsParams) (*ConceptsResponse, error) {
-	body := &url.Values{}
-
-	if len(params.Text) > 0 {
-		body.Add("text", params.Text)
-	} else if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else {
-		return nil, errors.New("you must either provide url or text")
-	}
+	return c.ConceptsContext(context.Background(), params)
+}
 
-	if len(params.Language) > 0 {
-		body.Add("language", params.Language)
+// ConceptsContext is like Concepts but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) ConceptsContext(ctx context.Context, params *ConceptsParams, opts ...CallOption) (*ConceptsResponse, error) {
+	body, err := c.encode(EndpointConcepts, inputOf(params.Input, params.Text, "", params.URL), params)
+	if err != nil {
+		return nil, err
 	}
 
 	concepts := &ConceptsResponse{}
-	err := c.call("/concepts", body, concepts)
+	err = c.call(ctx, "/concepts", body, concepts, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,322 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewClientFromEnv.
+const (
+	EnvApplicationID  = "AYLIEN_APP_ID"
+	EnvApplicationKey = "AYLIEN_APP_KEY"
+	EnvBaseURL        = "AYLIEN_BASE_URL"
+	EnvUseHTTPS       = "AYLIEN_USE_HTTPS"
+	EnvTimeout        = "AYLIEN_TIMEOUT"
+	EnvMaxAttempts    = "AYLIEN_MAX_ATTEMPTS"
+	EnvLanguage       = "AYLIEN_LANGUAGE"
+)
+
+// A RetryConfig is the configuration of a RetryPolicy.
+// Durations are strings such as "500ms" or "1m".
+type RetryConfig struct {
+	MaxAttempts int    `json:"max_attempts"`
+	MinBackoff  string `json:"min_backoff"`
+	MaxBackoff  string `json:"max_backoff"`
+	MaxWait     string `json:"max_wait"`
+}
+
+// A Profile is a named client configuration of a Config.
+type Profile struct {
+	ApplicationID  string `json:"application_id"`
+	ApplicationKey string `json:"application_key"`
+
+	// BaseURL is the URL of the Text API, see WithBaseURL. Default is the public API.
+	BaseURL string `json:"base_url"`
+
+	// UseHTTPS is whether to use HTTPS. Default is true.
+	UseHTTPS *bool `json:"use_https"`
+
+	// Timeout is the default timeout of a call, such as "10s", see WithTimeout.
+	Timeout string `json:"timeout"`
+
+	// Retry, if not nil, enables retries, see WithRetryPolicy.
+	Retry *RetryConfig `json:"retry"`
+
+	// Language is the default language, see WithDefaultLanguage.
+	Language string `json:"language"`
+}
+
+// profile is a Profile without its methods, printed by them once redacted.
+type profile Profile
+
+// redacted returns p with its application key redacted.
+func (p Profile) redacted() profile {
+	if len(p.ApplicationKey) > 0 {
+		p.ApplicationKey = redactedKey
+	}
+	return profile(p)
+}
+
+// String returns p with its application key redacted.
+func (p Profile) String() string {
+	return fmt.Sprint(p.redacted())
+}
+
+// Format makes every fmt verb print p with its application key redacted.
+func (p Profile) Format(f fmt.State, verb rune) {
+	out := fmt.Sprintf(fmt.FormatString(f, verb), p.redacted())
+	io.WriteString(f, strings.Replace(out, "textapi.profile{", "textapi.Profile{", 1))
+}
+
+// LogValue makes log/slog log p with its application key redacted.
+func (p Profile) LogValue() slog.Value {
+	return slog.AnyValue(p.redacted())
+}
+
+// A Config is a set of named profiles, typically loaded from a JSON file with LoadConfig:
+//
+//	{
+//	  "default_profile": "prod",
+//	  "profiles": {
+//	    "prod": {
+//	      "application_id": "...",
+//	      "application_key": "...",
+//	      "timeout": "10s",
+//	      "retry": {"max_attempts": 4, "max_wait": "1m"},
+//	      "language": "en"
+//	    },
+//	    "local": {
+//	      "application_id": "test",
+//	      "application_key": "test",
+//	      "base_url": "http://localhost:8080"
+//	    }
+//	  }
+//	}
+type Config struct {
+	// DefaultProfile is the profile used when no profile name is given.
+	DefaultProfile string             `json:"default_profile"`
+	Profiles       map[string]Profile `json:"profiles"`
+}
+
+// LoadConfig reads the Config stored as JSON in the file at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Profile returns the profile called name, or the default profile if name is empty.
+func (c *Config) Profile(name string) (Profile, error) {
+	if len(name) == 0 {
+		name = c.DefaultProfile
+	}
+	if len(name) == 0 {
+		return Profile{}, errors.New("no profile name given and no default_profile set")
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for n := range c.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Profile{}, fmt.Errorf("profile %q not found, available profiles are %s", name, strings.Join(names, ", "))
+	}
+	return profile, nil
+}
+
+// NewClient returns a new client configured by the profile called name,
+// or by the default profile if name is empty. opts are applied after the profile.
+func (c *Config) NewClient(name string, opts ...Option) (*Client, error) {
+	profile, err := c.Profile(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(name) == 0 {
+		name = c.DefaultProfile
+	}
+
+	client, err := profile.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return client, nil
+}
+
+// NewClientFromProfile loads the Config stored in the file at path and returns
+// a new client configured by its profile called name.
+func NewClientFromProfile(path, name string, opts ...Option) (*Client, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return config.NewClient(name, opts...)
+}
+
+// NewClient returns a new client configured by p. opts are applied after p.
+func (p Profile) NewClient(opts ...Option) (*Client, error) {
+	if len(p.ApplicationID) == 0 {
+		return nil, errors.New("application_id is required")
+	}
+	if len(p.ApplicationKey) == 0 {
+		return nil, errors.New("application_key is required")
+	}
+
+	useHTTPS := true
+	if p.UseHTTPS != nil {
+		useHTTPS = *p.UseHTTPS
+	}
+
+	var profileOpts []Option
+	if len(p.BaseURL) > 0 {
+		if _, err := parseBaseURL(p.BaseURL, useHTTPS); err != nil {
+			return nil, fmt.Errorf("invalid base_url %q: %w", p.BaseURL, err)
+		}
+		profileOpts = append(profileOpts, WithBaseURL(p.BaseURL))
+	}
+	if len(p.Timeout) > 0 {
+		timeout, err := parseDuration("timeout", p.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		profileOpts = append(profileOpts, WithTimeout(timeout))
+	}
+	if p.Retry != nil {
+		if p.Retry.MaxAttempts < 0 {
+			return nil, fmt.Errorf("invalid retry.max_attempts %d: must not be negative", p.Retry.MaxAttempts)
+		}
+		policy := RetryPolicy{MaxAttempts: p.Retry.MaxAttempts}
+		for _, d := range []struct {
+			name  string
+			value string
+			field *time.Duration
+		}{
+			{"retry.min_backoff", p.Retry.MinBackoff, &policy.MinBackoff},
+			{"retry.max_backoff", p.Retry.MaxBackoff, &policy.MaxBackoff},
+			{"retry.max_wait", p.Retry.MaxWait, &policy.MaxWait},
+		} {
+			if len(d.value) == 0 {
+				continue
+			}
+			v, err := parseDuration(d.name, d.value)
+			if err != nil {
+				return nil, err
+			}
+			*d.field = v
+		}
+		profileOpts = append(profileOpts, WithRetryPolicy(policy))
+	}
+	if len(p.Language) > 0 {
+		if err := validate("language", p.Language, languages); err != nil {
+			return nil, err
+		}
+		profileOpts = append(profileOpts, WithDefaultLanguage(Language(p.Language)))
+	}
+
+	return NewClient(Auth{p.ApplicationID, p.ApplicationKey}, useHTTPS, append(profileOpts, opts...)...)
+}
+
+// NewClientFromEnv returns a new client configured by environment variables:
+//
+//	AYLIEN_APP_ID        application ID, required
+//	AYLIEN_APP_KEY       application key, required
+//	AYLIEN_BASE_URL      URL of the Text API, see WithBaseURL
+//	AYLIEN_USE_HTTPS     whether to use HTTPS, true by default
+//	AYLIEN_TIMEOUT       default timeout of a call, e.g. 10s, see WithTimeout
+//	AYLIEN_MAX_ATTEMPTS  maximum number of attempts of a call, see WithRetryPolicy
+//	AYLIEN_LANGUAGE      default language, see WithDefaultLanguage
+//
+// opts are applied after the environment.
+func NewClientFromEnv(opts ...Option) (*Client, error) {
+	var p Profile
+	var missing []string
+	if p.ApplicationID = os.Getenv(EnvApplicationID); len(p.ApplicationID) == 0 {
+		missing = append(missing, EnvApplicationID)
+	}
+	if p.ApplicationKey = os.Getenv(EnvApplicationKey); len(p.ApplicationKey) == 0 {
+		missing = append(missing, EnvApplicationKey)
+	}
+	if len(missing) == 1 {
+		return nil, fmt.Errorf("environment variable %s is not set", missing[0])
+	}
+	if len(missing) > 1 {
+		return nil, fmt.Errorf("environment variables %s are not set", strings.Join(missing, " and "))
+	}
+
+	if p.Language = os.Getenv(EnvLanguage); len(p.Language) > 0 {
+		if err := validate(EnvLanguage, p.Language, languages); err != nil {
+			return nil, err
+		}
+	}
+
+	if v := os.Getenv(EnvUseHTTPS); len(v) > 0 {
+		useHTTPS, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: must be true or false", EnvUseHTTPS, v)
+		}
+		p.UseHTTPS = &useHTTPS
+	}
+	if p.BaseURL = os.Getenv(EnvBaseURL); len(p.BaseURL) > 0 {
+		if _, err := parseBaseURL(p.BaseURL, p.UseHTTPS == nil || *p.UseHTTPS); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", EnvBaseURL, p.BaseURL, err)
+		}
+	}
+	if p.Timeout = os.Getenv(EnvTimeout); len(p.Timeout) > 0 {
+		if _, err := parseDuration(EnvTimeout, p.Timeout); err != nil {
+			return nil, err
+		}
+	}
+	if v := os.Getenv(EnvMaxAttempts); len(v) > 0 {
+		attempts, err := strconv.Atoi(v)
+		if err != nil || attempts < 1 {
+			return nil, fmt.Errorf("invalid %s %q: must be a positive integer", EnvMaxAttempts, v)
+		}
+		p.Retry = &RetryConfig{MaxAttempts: attempts}
+	}
+
+	return p.NewClient(opts...)
+}
+
+// parseDuration parses the value of the setting called name as a non-negative duration.
+func parseDuration(name, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a duration such as 10s", name, value)
+	}
+	return d, nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClientFromEnv(t *testing.T) {
+	var language string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		language = r.FormValue("language")
+		fmt.Fprintln(w, "{}")
+	}))
+	defer server.Close()
+
+	t.Setenv(EnvApplicationID, "")
+	t.Setenv(EnvApplicationKey, "")
+	if _, err := NewClientFromEnv(); err == nil || err.Error() != "environment variables AYLIEN_APP_ID and AYLIEN_APP_KEY are not set" {
+		t.Errorf("unexpected error %v", err)
+	}
+	t.Setenv(EnvApplicationID, "test")
+	if _, err := NewClientFromEnv(); err == nil || err.Error() != "environment variable AYLIEN_APP_KEY is not set" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	t.Setenv(EnvApplicationID, "test")
+	t.Setenv(EnvApplicationKey, "test")
+	t.Setenv(EnvBaseURL, server.URL)
+	t.Setenv(EnvTimeout, "5s")
+	t.Setenv(EnvMaxAttempts, "3")
+	t.Setenv(EnvLanguage, "de")
+	c, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.baseURL != server.URL || c.timeout != 5*time.Second || c.retry == nil || c.retry.MaxAttempts != 3 {
+		t.Errorf("environment not applied: %+v", c)
+	}
+	if _, err := c.Concepts(&ConceptsParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	if language != "de" {
+		t.Errorf("default language not sent, got %q", language)
+	}
+	if _, err := c.Concepts(&ConceptsParams{Text: "text", Language: "fr"}); err != nil || language != "fr" {
+		t.Errorf("params language must take precedence, got %q", language)
+	}
+
+	for name, value := range map[string]string{
+		EnvTimeout:     "soon",
+		EnvMaxAttempts: "0",
+		EnvUseHTTPS:    "maybe",
+		EnvBaseURL:     "ftp://example.com",
+		EnvLanguage:    "klingon",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			_, err := NewClientFromEnv()
+			if err == nil || !strings.Contains(err.Error(), name) {
+				t.Errorf("error must name %s, got %v", name, err)
+			}
+		})
+	}
+}
+
+const testConfig = `{
+  "default_profile": "prod",
+  "profiles": {
+    "prod": {
+      "application_id": "prod-id",
+      "application_key": "prod-key",
+      "timeout": "10s",
+      "retry": {"max_attempts": 5, "max_wait": "1m"},
+      "language": "en"
+    },
+    "local": {
+      "application_id": "test",
+      "application_key": "test",
+      "base_url": "http://localhost:8080/api/v1/",
+      "use_https": false
+    },
+    "broken": {
+      "application_id": "test",
+      "application_key": "test",
+      "retry": {"min_backoff": "1 second"}
+    },
+    "anonymous": {
+      "application_id": "test"
+    },
+    "klingon": {
+      "application_id": "test",
+      "application_key": "test",
+      "language": "tlh"
+    }
+  }
+}`
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "textapi.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigProfiles(t *testing.T) {
+	path := writeConfig(t, testConfig)
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prod, err := config.NewClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prod.baseURL != "https://api.aylien.com/api/v1" || prod.timeout != 10*time.Second ||
+		prod.retry.MaxAttempts != 5 || prod.retry.MaxWait != time.Minute || prod.defaultLanguage != "en" {
+		t.Errorf("prod profile not applied: %+v", prod)
+	}
+
+	local, err := NewClientFromProfile(path, "local", WithTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local.baseURL != "http://localhost:8080/api/v1" || local.useHTTPS || local.timeout != time.Second {
+		t.Errorf("local profile not applied: %+v", local)
+	}
+
+	for name, expected := range map[string]string{
+		"broken":    `profile "broken": invalid retry.min_backoff "1 second"`,
+		"anonymous": `profile "anonymous": application_key is required`,
+		"klingon":   `profile "klingon": invalid language "tlh", accepted values are en, de, fr, es, it, pt and auto`,
+		"staging":   `profile "staging" not found, available profiles are anonymous, broken, klingon, local, prod`,
+	} {
+		if _, err := config.NewClient(name); err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("did not return error")
+	}
+	path := writeConfig(t, `{"profiles": {"prod": {"application_secret": "x"}}}`)
+	if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "application_secret") {
+		t.Errorf("unknown settings must be reported, got %v", err)
+	}
+	config, _ := LoadConfig(writeConfig(t, `{"profiles": {}}`))
+	if _, err := config.NewClient(""); err == nil {
+		t.Error("did not return error")
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An Endpoint is the name of a Text API endpoint, as used by Combined and Analyze.
+type Endpoint string
+
+// Endpoints of the Text API. The first eight can be called through Combined.
+const (
+	EndpointExtract              Endpoint = "extract"
+	EndpointSummarize            Endpoint = "summarize"
+	EndpointConcepts             Endpoint = "concepts"
+	EndpointEntities             Endpoint = "entities"
+	EndpointHashtags             Endpoint = "hashtags"
+	EndpointLanguage             Endpoint = "language"
+	EndpointSentiment            Endpoint = "sentiment"
+	EndpointClassify             Endpoint = "classify"
+	EndpointMicroformats         Endpoint = "microformats"
+	EndpointImageTags            Endpoint = "image-tags"
+	EndpointRelated              Endpoint = "related"
+	EndpointUnsupervisedClassify Endpoint = "classify/unsupervised"
+	EndpointClassifyIABQAG       Endpoint = "classify/iab-qag"
+	EndpointClassifyIPTC         Endpoint = "classify/iptc-subjectcode"
+)
+
+// combinedEndpoints are the endpoints that can be called through /combined.
+var combinedEndpoints = []string{
+	"extract", "summarize", "concepts", "entities", "hashtags", "language", "sentiment", "classify",
+}
+
+var endpoints = []string{
+	"extract", "summarize", "concepts", "entities", "hashtags", "language", "sentiment", "classify",
+	"microformats", "image-tags", "related", "classify/unsupervised", "classify/iab-qag", "classify/iptc-subjectcode",
+}
+
+// A Language is a language code accepted by the endpoints that take a language.
+type Language string
+
+// Languages accepted by the Text API. LanguageAuto detects the language of the document.
+const (
+	LanguageEnglish    Language = "en"
+	LanguageGerman     Language = "de"
+	LanguageFrench     Language = "fr"
+	LanguageSpanish    Language = "es"
+	LanguageItalian    Language = "it"
+	LanguagePortuguese Language = "pt"
+	LanguageAuto       Language = "auto"
+)
+
+var languages = []string{"en", "de", "fr", "es", "it", "pt", "auto"}
+
+// A SentimentMode is the analysis mode of Sentiment.
+type SentimentMode string
+
+const (
+	// SentimentModeTweet is suitable for short texts. It is the default.
+	SentimentModeTweet SentimentMode = "tweet"
+
+	// SentimentModeDocument is suitable for longer bodies of text.
+	SentimentModeDocument SentimentMode = "document"
+)
+
+var sentimentModes = []string{"tweet", "document"}
+
+// A SummarizeMode is the mode of Summarize.
+type SummarizeMode string
+
+const (
+	// SummarizeModeDefault is the default.
+	SummarizeModeDefault SummarizeMode = "default"
+
+	// SummarizeModeShort produces relatively shorter sentences.
+	SummarizeModeShort SummarizeMode = "short"
+)
+
+var summarizeModes = []string{"default", "short"}
+
+// A Taxonomy is a taxonomy ClassifyByTaxonomy classifies documents into.
+type Taxonomy string
+
+const (
+	// TaxonomyIABQAG is the IAB Quality Assurance Guidelines taxonomy.
+	TaxonomyIABQAG Taxonomy = "iab-qag"
+
+	// TaxonomyIPTCSubjectCode is the IPTC News Codes taxonomy.
+	TaxonomyIPTCSubjectCode Taxonomy = "iptc-subjectcode"
+)
+
+var taxonomies = []string{"iab-qag", "iptc-subjectcode"}
+
+// Valid returns whether e is an endpoint of the Text API.
+func (e Endpoint) Valid() bool { return contains(endpoints, string(e)) }
+
+// Valid returns whether l is a language accepted by the Text API.
+func (l Language) Valid() bool { return contains(languages, string(l)) }
+
+// Valid returns whether m is a sentiment mode accepted by the Text API.
+func (m SentimentMode) Valid() bool { return contains(sentimentModes, string(m)) }
+
+// Valid returns whether m is a summarize mode accepted by the Text API.
+func (m SummarizeMode) Valid() bool { return contains(summarizeModes, string(m)) }
+
+// Valid returns whether t is a taxonomy accepted by the Text API.
+func (t Taxonomy) Valid() bool { return contains(taxonomies, string(t)) }
+
+// validate returns an error listing the accepted values if value, called name, is not one of them.
+// An empty value is valid, since every such parameter has a default.
+func validate(name, value string, accepted []string) error {
+	if len(value) == 0 || contains(accepted, value) {
+		return nil
+	}
+	return fmt.Errorf("invalid %s %q, accepted values are %s", name, value, join(accepted))
+}
+
+// join returns values as a list such as "a, b and c".
+func join(values []string) string {
+	if len(values) < 2 {
+		return strings.Join(values, "")
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " and " + values[len(values)-1]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
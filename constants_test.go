@@ -0,0 +1,102 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	if !EndpointClassifyIABQAG.Valid() || Endpoint("classify/").Valid() {
+		t.Error("unexpected endpoint validity")
+	}
+	if !LanguageAuto.Valid() || Language("xx").Valid() {
+		t.Error("unexpected language validity")
+	}
+	if !SentimentModeDocument.Valid() || SentimentMode("long").Valid() {
+		t.Error("unexpected sentiment mode validity")
+	}
+	if !SummarizeModeShort.Valid() || SummarizeMode("long").Valid() {
+		t.Error("unexpected summarize mode validity")
+	}
+	if !TaxonomyIPTCSubjectCode.Valid() || Taxonomy("dewey").Valid() {
+		t.Error("unexpected taxonomy validity")
+	}
+}
+
+func TestValidationBeforeCall(t *testing.T) {
+	c, _ := NewClient(auth, false, WithTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		t.Errorf("unexpected request to %s", req.URL)
+		return nil, http.ErrNotSupported
+	})))
+
+	for _, test := range []struct {
+		call func() error
+		err  string
+	}{
+		{
+			func() error { _, err := c.Sentiment(&SentimentParams{Text: "text", Mode: "long"}); return err },
+			`invalid sentiment mode "long", accepted values are tweet and document`,
+		},
+		{
+			func() error {
+				_, err := c.Summarize(&SummarizeParams{URL: "http://example.com", Mode: "long"})
+				return err
+			},
+			`invalid summarize mode "long", accepted values are default and short`,
+		},
+		{
+			func() error { _, err := c.Concepts(&ConceptsParams{Text: "text", Language: "xx"}); return err },
+			`invalid language "xx", accepted values are en, de, fr, es, it, pt and auto`,
+		},
+		{
+			func() error {
+				_, err := c.ClassifyByTaxonomy(&ClassifyByTaxonomyParams{Text: "text", Taxonomy: "dewey"})
+				return err
+			},
+			`invalid taxonomy "dewey", accepted values are iab-qag and iptc-subjectcode`,
+		},
+		{
+			func() error {
+				_, err := c.ClassifyByTaxonomy(&ClassifyByTaxonomyParams{Text: "text"})
+				return err
+			},
+			`you must specify the taxonomy, accepted values are iab-qag and iptc-subjectcode`,
+		},
+		{
+			func() error {
+				_, err := c.Combined(&CombinedParams{Text: "text", Endpoints: []Endpoint{EndpointSentiment, EndpointRelated}})
+				return err
+			},
+			`invalid endpoint "related", accepted values are extract, summarize, concepts, entities, hashtags, language, sentiment and classify`,
+		},
+	} {
+		if err := test.call(); err == nil || err.Error() != test.err {
+			t.Errorf("expected error %q, got %v", test.err, err)
+		}
+	}
+}
+
+func TestDefaultLanguageValidation(t *testing.T) {
+	if _, err := NewClient(auth, false, WithDefaultLanguage("xx")); err == nil {
+		t.Error("expected an error")
+	}
+	if _, err := NewClient(auth, false, WithDefaultLanguage(LanguageGerman)); err != nil {
+		t.Error(err)
+	}
+}
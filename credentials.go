@@ -0,0 +1,243 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A CredentialsProvider supplies the application ID and key of every request
+// sent by a client. Implementations must be safe for concurrent use.
+type CredentialsProvider interface {
+	// Credentials returns the credentials to use for the next request.
+	Credentials(ctx context.Context) (Auth, error)
+
+	// Report is called after every request sent with auth, with the rate limits
+	// of its response and its error, if any. If it returns true, a failed
+	// request is retried right away, with the credentials Credentials returns next.
+	Report(auth Auth, limits RateLimits, err error) bool
+}
+
+// WithCredentials makes the client get the credentials of every request from provider,
+// instead of using the Auth given to NewClient.
+//
+// Since each application key has its own quota, the quota tracking of
+// WithRateLimit is disabled when this option is used.
+func WithCredentials(provider CredentialsProvider) Option {
+	return func(c *Client) error {
+		if provider == nil {
+			return errors.New("credentials provider must not be nil")
+		}
+		c.credentials = provider
+		return nil
+	}
+}
+
+// staticCredentials always provides the same Auth.
+type staticCredentials struct {
+	auth Auth
+}
+
+func (s staticCredentials) Credentials(ctx context.Context) (Auth, error) {
+	return s.auth, nil
+}
+
+func (s staticCredentials) Report(auth Auth, limits RateLimits, err error) bool {
+	return false
+}
+
+// String returns s with its application key redacted.
+func (s staticCredentials) String() string {
+	return s.auth.String()
+}
+
+// Format makes every fmt verb print s with its application key redacted.
+func (s staticCredentials) Format(f fmt.State, verb rune) {
+	io.WriteString(f, s.String())
+}
+
+// keyCooldown is how long an exhausted key is left aside when its reset time is unknown.
+const keyCooldown = time.Minute
+
+// A KeyUsage describes the use of an application key of a KeyPool.
+type KeyUsage struct {
+	ApplicationID string
+
+	// Requests is the number of requests sent with the key,
+	// Errors how many of them failed, and RateLimited how many
+	// of them were rejected because of the rate limit.
+	Requests    int
+	Errors      int
+	RateLimited int
+
+	// RateLimits is the rate limits of the last response to a request sent with the key.
+	RateLimits RateLimits
+
+	// ExhaustedUntil is the time until which the key is not used,
+	// or the zero time if the key is available.
+	ExhaustedUntil time.Time
+}
+
+// A KeyPool is a CredentialsProvider that spreads calls over several application keys.
+//
+// The pool uses the same key until it is exhausted, that is until a request
+// sent with it is rejected with a rate limit error or its X-RateLimit-Remaining
+// reaches zero. It then rotates to the next available key, and puts the
+// exhausted key back once its X-RateLimit-Reset time has passed.
+// Calls rejected because of the rate limit are retried with the next key.
+type KeyPool struct {
+	mu      sync.Mutex
+	keys    []*KeyUsage
+	auths   []Auth
+	current int
+}
+
+// NewKeyPool returns a KeyPool using keys, in order.
+func NewKeyPool(keys ...Auth) (*KeyPool, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("you must provide at least one application key")
+	}
+
+	p := &KeyPool{}
+	for i, auth := range keys {
+		if len(auth.ApplicationID) == 0 || len(auth.ApplicationKey) == 0 {
+			return nil, fmt.Errorf("invalid application ID or application key at index %d", i)
+		}
+		p.auths = append(p.auths, auth)
+		p.keys = append(p.keys, &KeyUsage{ApplicationID: auth.ApplicationID})
+	}
+	return p, nil
+}
+
+// String returns the application IDs of p, with their keys redacted.
+func (p *KeyPool) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	auths := make([]string, len(p.auths))
+	for i, auth := range p.auths {
+		auths[i] = auth.String()
+	}
+	return "KeyPool[" + strings.Join(auths, " ") + "]"
+}
+
+// Format makes every fmt verb print p with its application keys redacted.
+func (p *KeyPool) Format(f fmt.State, verb rune) {
+	io.WriteString(f, p.String())
+}
+
+// Credentials implements CredentialsProvider. If all keys are exhausted,
+// it returns an error matching ErrRateLimited.
+func (p *KeyPool) Credentials(ctx context.Context) (Auth, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if i, ok := p.available(time.Now()); ok {
+		p.current = i
+		return p.auths[i], nil
+	}
+
+	soonest := p.keys[0].ExhaustedUntil
+	for _, k := range p.keys {
+		if k.ExhaustedUntil.Before(soonest) {
+			soonest = k.ExhaustedUntil
+		}
+	}
+	return Auth{}, fmt.Errorf("%w: all application keys are exhausted until %v", ErrRateLimited, soonest)
+}
+
+// Report implements CredentialsProvider.
+func (p *KeyPool) Report(auth Auth, limits RateLimits, err error) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i := p.index(auth)
+	if i < 0 {
+		return false
+	}
+	k := p.keys[i]
+
+	k.Requests++
+	if err != nil {
+		k.Errors++
+	}
+	if limits.Limit > 0 {
+		k.RateLimits = limits
+	}
+
+	rateLimited := errors.Is(err, ErrRateLimited)
+	if rateLimited {
+		k.RateLimited++
+	}
+	if !rateLimited && (limits.Limit <= 0 || limits.Remaining > 0) {
+		return false
+	}
+
+	now := time.Now()
+	k.ExhaustedUntil = limits.ResetTime()
+	if !k.ExhaustedUntil.After(now) {
+		k.ExhaustedUntil = now.Add(keyCooldown)
+	}
+	if i == p.current {
+		p.current = (i + 1) % len(p.keys)
+	}
+
+	_, ok := p.available(now)
+	return rateLimited && ok
+}
+
+// Usage returns the usage of every key of the pool, in order.
+func (p *KeyPool) Usage() []KeyUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	usage := make([]KeyUsage, len(p.keys))
+	for i, k := range p.keys {
+		usage[i] = *k
+		if !usage[i].ExhaustedUntil.After(now) {
+			usage[i].ExhaustedUntil = time.Time{}
+		}
+	}
+	return usage
+}
+
+// available returns the index of the first key available at now, starting from the current one.
+func (p *KeyPool) available(now time.Time) (int, bool) {
+	for n := 0; n < len(p.keys); n++ {
+		i := (p.current + n) % len(p.keys)
+		if !p.keys[i].ExhaustedUntil.After(now) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (p *KeyPool) index(auth Auth) int {
+	for i, a := range p.auths {
+		if a == auth {
+			return i
+		}
+	}
+	return -1
+}
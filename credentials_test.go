@@ -0,0 +1,156 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyPool(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-AYLIEN-TextAPI-Application-ID")
+		mu.Lock()
+		requests[id]++
+		n := requests[id]
+		mu.Unlock()
+
+		w.Header().Set("X-RateLimit-Limit", "2")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		if id == "first" {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprintln(w, `{"error": "rate limit exceeded"}`)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(2-n))
+		fmt.Fprintln(w, "{}")
+	}))
+	defer server.Close()
+
+	pool, err := NewKeyPool(Auth{"first", "key1"}, Auth{"second", "key2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(Auth{}, false, WithBaseURL(server.URL), WithCredentials(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res Response
+	if _, err := c.SentimentContext(context.Background(), &SentimentParams{Text: "text"}, CaptureResponse(&res)); err != nil {
+		t.Fatal(err)
+	}
+	if res.Attempts != 2 || res.RequestHeader.Get("X-AYLIEN-TextAPI-Application-ID") != "second" {
+		t.Errorf("call not retried with the next key: %+v", res)
+	}
+
+	usage := pool.Usage()
+	if usage[0].ApplicationID != "first" || usage[0].Requests != 1 || usage[0].RateLimited != 1 ||
+		usage[0].ExhaustedUntil.Unix() != reset {
+		t.Errorf("unexpected usage %+v", usage[0])
+	}
+	if usage[1].Requests != 1 || usage[1].Errors != 0 || usage[1].RateLimits.Remaining != 1 ||
+		!usage[1].ExhaustedUntil.IsZero() {
+		t.Errorf("unexpected usage %+v", usage[1])
+	}
+
+	if _, err := c.Sentiment(&SentimentParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	if usage := pool.Usage(); usage[1].ExhaustedUntil.Unix() != reset {
+		t.Errorf("key must be exhausted once X-RateLimit-Remaining is 0: %+v", usage[1])
+	}
+
+	_, err = c.Sentiment(&SentimentParams{Text: "text"})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+	if requests["first"] != 1 || requests["second"] != 2 {
+		t.Errorf("exhausted keys must not be used: %v", requests)
+	}
+}
+
+func TestKeyPoolPutsKeysBack(t *testing.T) {
+	pool, _ := NewKeyPool(Auth{"first", "key1"}, Auth{"second", "key2"})
+	first, _ := pool.Credentials(context.Background())
+	limits := RateLimits{Limit: 10, Remaining: 0, Reset: int(time.Now().Add(-time.Second).Unix())}
+	if pool.Report(first, limits, nil) {
+		t.Error("successful requests must not be retried")
+	}
+
+	if usage := pool.Usage(); usage[0].ExhaustedUntil.IsZero() {
+		t.Error("key with an unknown reset time must cool down")
+	}
+	if next, _ := pool.Credentials(context.Background()); next.ApplicationID != "second" {
+		t.Errorf("expected next key, got %v", next)
+	}
+
+	pool.keys[0].ExhaustedUntil = time.Now().Add(-time.Millisecond)
+	pool.Report(Auth{"second", "key2"}, RateLimits{}, &APIError{StatusCode: 429})
+	if next, _ := pool.Credentials(context.Background()); next.ApplicationID != "first" {
+		t.Errorf("key must be put back after its reset time, got %v", next)
+	}
+}
+
+func TestNewKeyPool(t *testing.T) {
+	if _, err := NewKeyPool(); err == nil {
+		t.Error("did not return error")
+	}
+	if _, err := NewKeyPool(Auth{"first", "key1"}, Auth{"second", ""}); err == nil {
+		t.Error("did not return error")
+	}
+	if _, err := NewClient(Auth{}, false); err == nil {
+		t.Error("did not return error")
+	}
+}
+
+func TestCredentialsRedacted(t *testing.T) {
+	c, _ := NewClient(Auth{"app", "secret-key"}, false)
+	pool, _ := NewKeyPool(Auth{"app-1", "secret-key-1"}, Auth{"app-2", "secret-key-2"})
+	pooled, _ := NewClient(Auth{}, false, WithCredentials(pool))
+	profile := Profile{ApplicationID: "app", ApplicationKey: "secret-key", Timeout: "10s"}
+	config := &Config{DefaultProfile: "prod", Profiles: map[string]Profile{"prod": profile}}
+
+	for _, v := range []interface{}{c, pool, pooled, staticCredentials{Auth{"app", "secret-key"}}, profile, config} {
+		for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+			out := fmt.Sprintf(format, v)
+			if strings.Contains(out, "secret-key") || !strings.Contains(out, "app") {
+				t.Errorf("%s: %s", format, out)
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	slog.New(slog.NewJSONHandler(buf, nil)).Info("config", "profile", profile)
+	if strings.Contains(buf.String(), "secret-key") || !strings.Contains(buf.String(), `"application_key":"REDACTED"`) {
+		t.Errorf("profile not redacted in logs: %s", buf)
+	}
+}
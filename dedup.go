@@ -0,0 +1,146 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// WithDeduplication makes the client merge identical concurrent calls, that is
+// calls to the same endpoint with the same form values, into a single request.
+// Every caller gets the result, or the error, of the shared request.
+//
+// A caller whose context is done stops waiting right away. The shared request
+// is only cancelled once all of its callers have stopped waiting.
+func WithDeduplication() Option {
+	return func(c *Client) error {
+		c.flights = &flightGroup{flights: map[string]*flight{}}
+		return nil
+	}
+}
+
+// A flightGroup tracks the requests in flight, by endpoint and encoded form.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// A flight is a request shared by identical calls.
+type flight struct {
+	done    chan struct{}
+	call    *Call
+	result  json.RawMessage
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// deduplicated returns an Invoker that merges identical concurrent calls into one call to next.
+func (c *Client) deduplicated(next Invoker) Invoker {
+	return func(ctx context.Context, call *Call) error {
+		if call.Result == nil {
+			return next(ctx, call)
+		}
+
+		key := call.Endpoint + "?" + call.Form.Encode()
+		f, shared := c.flights.join(ctx, key, call, next)
+
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			c.flights.leave(key, f)
+			return ctx.Err()
+		}
+		c.flights.leave(key, f)
+
+		*call.Response = *f.call.Response
+		call.Response.Shared = shared
+		if f.err != nil {
+			return f.err
+		}
+
+		call.body = f.result
+		if err := json.Unmarshal(f.result, call.Result); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+		}
+		return nil
+	}
+}
+
+// join returns the flight of key, starting it if there is none,
+// and whether it was already in flight.
+func (g *flightGroup) join(ctx context.Context, key string, call *Call, next Invoker) (*flight, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if f, ok := g.flights[key]; ok {
+		f.waiters++
+		return f, true
+	}
+
+	// The shared request is not cancelled with the context of the call
+	// that started it, since other calls may be waiting for it.
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	f := &flight{
+		done:    make(chan struct{}),
+		waiters: 1,
+		cancel:  cancel,
+		call: &Call{
+			Endpoint: call.Endpoint,
+			Form:     call.Form,
+			Header:   call.Header,
+			Response: &Response{},
+			options:  call.options,
+		},
+	}
+	f.call.Result = &f.result
+	g.flights[key] = f
+
+	go func() {
+		f.err = next(ctx, f.call)
+		cancel()
+		g.remove(key, f)
+		close(f.done)
+	}()
+	return f, false
+}
+
+// leave records that a caller stopped waiting for f,
+// and cancels f if it was the last one.
+func (g *flightGroup) leave(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	f.waiters--
+	if f.waiters == 0 {
+		f.cancel()
+		if g.flights[key] == f {
+			delete(g.flights, key)
+		}
+	}
+}
+
+func (g *flightGroup) remove(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+}
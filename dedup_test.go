@@ -0,0 +1,157 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBlockingServer returns a server answering /sentiment once release is closed.
+func newBlockingServer(release chan struct{}) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		fmt.Fprintln(w, `{"polarity": "positive"}`)
+	}))
+	return server, &requests
+}
+
+// waitForWaiters waits until n calls wait for the flight of key.
+func waitForWaiters(t *testing.T, g *flightGroup, key string, n int) {
+	for i := 0; i < 1000; i++ {
+		g.mu.Lock()
+		f, ok := g.flights[key]
+		joined := ok && f.waiters == n
+		g.mu.Unlock()
+		if joined {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d calls did not join the flight", n)
+}
+
+func TestDeduplication(t *testing.T) {
+	release := make(chan struct{})
+	server, requests := newBlockingServer(release)
+	defer server.Close()
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithDeduplication())
+
+	const n = 5
+	var wg sync.WaitGroup
+	var shared int32
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var r Response
+			sentiment, err := c.SentimentContext(context.Background(), &SentimentParams{Text: "text"}, CaptureResponse(&r))
+			if err == nil && sentiment.Polarity != "positive" {
+				err = errors.New("invalid response")
+			}
+			if r.Shared {
+				atomic.AddInt32(&shared, 1)
+			}
+			errs <- err
+		}()
+	}
+
+	waitForWaiters(t, c.flights, "/sentiment?text=text", n)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if *requests != 1 || shared != n-1 {
+		t.Errorf("unexpected %d requests and %d shared calls", *requests, shared)
+	}
+}
+
+func TestDeduplicationCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server, requests := newBlockingServer(release)
+	defer server.Close()
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithDeduplication())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.SentimentContext(ctx, &SentimentParams{Text: "text"})
+		first <- err
+	}()
+	waitForWaiters(t, c.flights, "/sentiment?text=text", 1)
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := c.Sentiment(&SentimentParams{Text: "text"})
+		second <- err
+	}()
+	waitForWaiters(t, c.flights, "/sentiment?text=text", 2)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	close(release)
+	if err := <-second; err != nil {
+		t.Error(err)
+	}
+	if *requests != 1 {
+		t.Errorf("unexpected %d requests", *requests)
+	}
+}
+
+func TestDeduplicationAllCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server, _ := newBlockingServer(release)
+	defer server.Close()
+	defer close(release)
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithDeduplication())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.SentimentContext(ctx, &SentimentParams{Text: "text"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error %v", err)
+	}
+	c.flights.mu.Lock()
+	defer c.flights.mu.Unlock()
+	if len(c.flights.flights) != 0 {
+		t.Error("flight not removed")
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// DocumentParams is the document analyzed by a Document.
+type DocumentParams struct {
+	// Either Input, URL or Text is required, see Input.
+	Input Input
+	URL   string
+	Text  string
+
+	// Title is the title of Text, required to summarize it.
+	Title string
+}
+
+// A Document analyzes a single text or URL with the endpoints supported by /combined,
+// and remembers the results.
+//
+// Endpoints declared with Prefetch are fetched along with the first endpoint
+// asked for, with a single call to /combined. An endpoint asked for on its own
+// is fetched with a call to the endpoint itself. Either way, each result is
+// only fetched once; failed calls are not remembered.
+//
+// A Document is safe for concurrent use, but its calls are sent one at a time.
+type Document struct {
+	client *Client
+	params DocumentParams
+
+	mu      sync.Mutex
+	pending []Endpoint
+	fetched map[Endpoint]bool
+	results AnalyzeResponse
+}
+
+// NewDocument returns a Document analyzing the input given by params.
+func (c *Client) NewDocument(params *DocumentParams) (*Document, error) {
+	input := inputOf(params.Input, params.Text, "", params.URL)
+	if err := input.checkSet(); err != nil {
+		return nil, err
+	}
+
+	// The input is sent with every call, so it is read once beforehand.
+	input, err := input.buffered()
+	if err != nil {
+		return nil, err
+	}
+	p := *params
+	p.Input = input
+
+	return &Document{
+		client:  c,
+		params:  p,
+		fetched: map[Endpoint]bool{},
+	}, nil
+}
+
+// Prefetch declares endpoints whose results will be asked for,
+// so that they are fetched together with the next one asked for.
+func (d *Document) Prefetch(endpoints ...Endpoint) error {
+	for _, e := range endpoints {
+		if !contains(combinedEndpoints, string(e)) {
+			return fmt.Errorf("invalid endpoint %q, accepted values are %s", e, join(combinedEndpoints))
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, e := range endpoints {
+		if !d.fetched[e] {
+			d.pending = append(d.pending, e)
+		}
+	}
+	return nil
+}
+
+// Article returns the article extracted from the document URL.
+func (d *Document) Article(ctx context.Context, opts ...CallOption) (*ExtractResponse, error) {
+	var r ExtractResponse
+	if err := d.get(ctx, EndpointExtract, func() { r = d.results.Article }, opts); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Summary returns the summary of the document.
+func (d *Document) Summary(ctx context.Context, opts ...CallOption) (*SummarizeResponse, error) {
+	var r SummarizeResponse
+	if err := d.get(ctx, EndpointSummarize, func() { r = d.results.Summary }, opts); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Concepts returns the concepts mentioned in the document.
+func (d *Document) Concepts(ctx context.Context, opts ...CallOption) (*ConceptsResponse, error) {
+	var r ConceptsResponse
+	if err := d.get(ctx, EndpointConcepts, func() { r = d.results.Concepts }, opts); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Entities returns the entities mentioned in the document.
+func (d *Document) Entities(ctx context.Context, opts ...CallOption) (*EntitiesResponse, error) {
+	var r EntitiesResponse
+	if err := d.get(ctx, EndpointEntities, func() { r = d.results.Entities }, opts); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Hashtags returns the hashtags suggested for the document.
+func (d *Document) Hashtags(ctx context.Context, opts ...CallOption) (*HashtagsResponse, error) {
+	var r HashtagsResponse
+	if err := d.get(ctx, EndpointHashtags, func() { r = d.results.Hashtags }, opts); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Language returns the language of the document.
+func (d *Document) Language(ctx context.Context, opts ...CallOption) (*LanguageResponse, error) {
+	var r LanguageResponse
+	if err := d.get(ctx, EndpointLanguage, func() { r = d.results.Language }, opts); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Sentiment returns the sentiment of the document.
+func (d *Document) Sentiment(ctx context.Context, opts ...CallOption) (*SentimentResponse, error) {
+	var r SentimentResponse
+	if err := d.get(ctx, EndpointSentiment, func() { r = d.results.Sentiment }, opts); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Classify returns the IPTC subject codes of the document.
+func (d *Document) Classify(ctx context.Context, opts ...CallOption) (*ClassifyResponse, error) {
+	var r ClassifyResponse
+	if err := d.get(ctx, EndpointClassify, func() { r = d.results.Classifications }, opts); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// get fetches endpoint if needed, then calls read with the lock held.
+func (d *Document) get(ctx context.Context, endpoint Endpoint, read func(), opts []CallOption) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.fetched[endpoint] {
+		if err := d.fetch(ctx, endpoint, opts); err != nil {
+			return err
+		}
+	}
+	read()
+	return nil
+}
+
+// fetch fetches endpoint and the pending endpoints, with a single call.
+func (d *Document) fetch(ctx context.Context, endpoint Endpoint, opts []CallOption) error {
+	endpoints := []Endpoint{endpoint}
+	seen := map[Endpoint]bool{endpoint: true}
+	for _, e := range d.pending {
+		if !seen[e] && !d.fetched[e] {
+			endpoints = append(endpoints, e)
+			seen[e] = true
+		}
+	}
+
+	d.pending = nil
+
+	if len(endpoints) == 1 {
+		set, err := d.client.analyzeOne(ctx, &d.results, endpoint, &AnalyzeParams{
+			Input: d.params.Input,
+			Title: d.params.Title,
+		}, opts)
+		if err != nil {
+			return err
+		}
+		set()
+		d.fetched[endpoint] = true
+		return nil
+	}
+
+	r, err := d.client.CombinedContext(ctx, &CombinedParams{
+		Input:     d.params.Input,
+		Endpoints: endpoints,
+	}, opts...)
+	if err != nil {
+		d.pending = endpoints[1:]
+		return err
+	}
+
+	// Endpoints missing from the response, or whose result could not be
+	// decoded, are fetched again the next time they are asked for.
+	d.results.Text = r.Text
+	for _, e := range endpoints {
+		if r.EndpointErr(e) == nil {
+			d.results.setCombined(e, r)
+			d.fetched[e] = true
+		}
+	}
+	return r.EndpointErr(endpoint)
+}
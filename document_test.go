@@ -0,0 +1,141 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newDocumentServer returns a server answering /combined, /sentiment and /entities,
+// and the list of the paths it was called with.
+func newDocumentServer() (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		mu.Lock()
+		paths = append(paths, r.URL.Path+"?"+strings.Join(r.PostForm["endpoint"], ","))
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/combined":
+			fmt.Fprint(w, `{"text": "text", "results": [
+				{"endpoint": "sentiment", "result": {"polarity": "negative"}},
+				{"endpoint": "entities", "result": {"entities": {"location": ["Dublin"]}}}
+			]}`)
+		case "/sentiment":
+			fmt.Fprint(w, `{"polarity": "positive"}`)
+		case "/concepts":
+			fmt.Fprint(w, `{"concepts": {}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+func TestDocumentCombined(t *testing.T) {
+	server, paths := newDocumentServer()
+	defer server.Close()
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL))
+
+	doc, err := c.NewDocument(&DocumentParams{Text: "text"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Prefetch("sentiment", "entities"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	sentiment, err := doc.Sentiment(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entities, err := doc.Entities(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sentiment.Polarity != "negative" || entities.Entities["location"][0] != "Dublin" {
+		t.Errorf("unexpected results %+v and %+v", sentiment, entities)
+	}
+	if p := paths(); len(p) != 1 || p[0] != "/combined?sentiment,entities" {
+		t.Errorf("unexpected calls %v", p)
+	}
+
+	if _, err := doc.Concepts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if p := paths(); len(p) != 2 || p[1] != "/concepts?" {
+		t.Errorf("unexpected calls %v", p)
+	}
+}
+
+func TestDocumentSingleEndpoint(t *testing.T) {
+	server, paths := newDocumentServer()
+	defer server.Close()
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL))
+
+	doc, _ := c.NewDocument(&DocumentParams{URL: "http://example.com"})
+	doc.Prefetch("sentiment")
+	for i := 0; i < 2; i++ {
+		sentiment, err := doc.Sentiment(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sentiment.Polarity != "positive" {
+			t.Error("invalid response")
+		}
+	}
+	if p := paths(); len(p) != 1 || p[0] != "/sentiment?" {
+		t.Errorf("unexpected calls %v", p)
+	}
+}
+
+func TestDocumentErrors(t *testing.T) {
+	server, paths := newDocumentServer()
+	defer server.Close()
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL))
+
+	if _, err := c.NewDocument(&DocumentParams{}); err == nil {
+		t.Error("expected an error without text or url")
+	}
+
+	doc, _ := c.NewDocument(&DocumentParams{Text: "text"})
+	if err := doc.Prefetch("microformats"); err == nil {
+		t.Error("expected an error for an endpoint not supported by /combined")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := doc.Hashtags(context.Background()); err == nil {
+			t.Error("expected an error")
+		}
+	}
+	if p := paths(); len(p) != 2 {
+		t.Errorf("failed calls remembered: %v", p)
+	}
+}
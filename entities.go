@@ -17,15 +17,15 @@ limitations under the License.
 package textapi
 
 import (
-	"errors"
-	"net/url"
+	"context"
 )
 
 // EntitiesParams is the set of parameters that defines a document whose entities needs to be extracted.
 type EntitiesParams struct {
-	// Either URL or Text is required.
-	URL  string
-	Text string
+	// Either Input, URL or Text is required, see Input.
+	Input Input
+	URL   string
+	Text  string
 }
 
 // A EntitiesResponse is the JSON description of entities extraction response.
@@ -36,18 +36,19 @@ type EntitiesResponse struct {
 
 // Entities extracts entities mentioned in the document defined by the given params information.
 func (c *Client) Entities(params *EntitiesParams) (*EntitiesResponse, error) {
-	body := &url.Values{}
-
-	if len(params.Text) > 0 {
-		body.Add("text", params.Text)
-	} else if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else {
-		return nil, errors.New("you must either provide url or text")
+	return c.EntitiesContext(context.Background(), params)
+}
+
+// EntitiesContext is like Entities but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) EntitiesContext(ctx context.Context, params *EntitiesParams, opts ...CallOption) (*EntitiesResponse, error) {
+	body, err := c.encode(EndpointEntities, inputOf(params.Input, params.Text, "", params.URL), params)
+	if err != nil {
+		return nil, err
 	}
 
 	entities := &EntitiesResponse{}
-	err := c.call("/entities", body, entities)
+	err = c.call(ctx, "/entities", body, entities, opts...)
 	if err != nil {
 		return nil, err
 	}
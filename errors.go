@@ -0,0 +1,117 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Errors that an *APIError matches with errors.Is, depending on its status.
+var (
+	// ErrUnauthorized is matched by 401 and 403 responses,
+	// e.g. when the application ID or key is wrong.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrRateLimited is matched by 429 responses, when the quota is exhausted.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrInvalidInput is matched by 4xx responses that reject the parameters
+	// of the call, e.g. an invalid URL or an unsupported language,
+	// and by a *ValidationError.
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrServer is matched by 5xx responses.
+	ErrServer = errors.New("server error")
+)
+
+// ErrInvalidResponse is returned, wrapped along with the decoding error,
+// when a successful response cannot be decoded.
+var ErrInvalidResponse = errors.New("invalid response")
+
+// ErrResponseTooLarge is returned when a response body exceeds the maximum
+// response size of the client.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// An APIError is returned when the Text API answers a call with an error status.
+type APIError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+
+	// Endpoint is the path of the endpoint that was called, e.g. /sentiment.
+	Endpoint string
+
+	// Message is the error message sent by the server,
+	// or the raw body if it is not a JSON Error.
+	Message string
+
+	// Body and Header are the raw body and headers of the response.
+	Body   []byte
+	Header http.Header
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if len(message) == 0 {
+		message = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s (status %d)", e.Endpoint, message, e.StatusCode)
+}
+
+// Is reports whether the status of e matches target,
+// which is one of ErrUnauthorized, ErrRateLimited, ErrInvalidInput or ErrServer.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrInvalidInput:
+		return e.StatusCode >= 400 && e.StatusCode < 500 &&
+			!e.Is(ErrUnauthorized) && !e.Is(ErrRateLimited)
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// A ValidationError is returned when the params of a call are invalid,
+// before the call is sent. It matches ErrInvalidInput with errors.Is.
+type ValidationError struct {
+	// Errors describe every invalid parameter.
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the errors of e.
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// Is reports whether target is ErrInvalidInput.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	params := &EntitiesParams{URL: "invalid"}
+	_, err := client.Entities(params)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.StatusCode != 400 || apiErr.Endpoint != "/entities" ||
+		apiErr.Message != "requirement failed: provided url is not valid." || len(apiErr.Body) == 0 {
+		t.Errorf("unexpected error %#v", apiErr)
+	}
+	if apiErr.Error() != "/entities: requirement failed: provided url is not valid. (status 400)" {
+		t.Errorf("unexpected message %q", apiErr.Error())
+	}
+	if !errors.Is(err, ErrInvalidInput) || errors.Is(err, ErrServer) {
+		t.Error("must only match ErrInvalidInput")
+	}
+
+	badClient, _ := NewClient(Auth{"wrongtest", "wrongtest"}, false, WithBaseURL(testServerURL))
+	_, err = badClient.Language(&LanguageParams{Text: "Hello"})
+	if !errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+	if !errors.As(err, &apiErr) || apiErr.Message != "Authentication failed\n" {
+		t.Errorf("raw body must be used as message, got %q", apiErr.Message)
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	for status, sentinel := range map[int]error{
+		401: ErrUnauthorized,
+		403: ErrUnauthorized,
+		429: ErrRateLimited,
+		400: ErrInvalidInput,
+		422: ErrInvalidInput,
+		500: ErrServer,
+		503: ErrServer,
+	} {
+		err := fmt.Errorf("wrapped: %w", &APIError{StatusCode: status})
+		for _, other := range []error{ErrUnauthorized, ErrRateLimited, ErrInvalidInput, ErrServer} {
+			if errors.Is(err, other) != (other == sentinel) {
+				t.Errorf("%d: errors.Is(%v) = %v", status, other, !(other == sentinel))
+			}
+		}
+	}
+}
+
+func TestInvalidResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"text": `)
+	}))
+	defer server.Close()
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL))
+	_, err := c.Sentiment(&SentimentParams{Text: "text"})
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("expected ErrInvalidResponse, got %v", err)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("decoding error must be kept, got %v", err)
+	}
+}
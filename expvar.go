@@ -0,0 +1,111 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"expvar"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ExpvarMetrics is a Metrics that publishes the activity of a client with package expvar.
+//
+// The published map holds the latest rate_limit_remaining and rate_limit_limit,
+// and one map per endpoint with the number of requests, the number of
+// responses per status class (status_2xx, status_4xx, ..., status_error),
+// the number of retries, and a cumulative latency histogram in seconds
+// (latency_seconds, keyed by upper bound, latency_seconds_sum and latency_seconds_count).
+type ExpvarMetrics struct {
+	vars *expvar.Map
+
+	mu        sync.Mutex
+	endpoints map[string]*expvar.Map
+}
+
+// NewExpvarMetrics returns an ExpvarMetrics published under name.
+// Like expvar.Publish, it panics if name is already in use.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return NewExpvarMetricsMap(expvar.NewMap(name))
+}
+
+// NewExpvarMetricsMap returns an ExpvarMetrics writing to vars, which it does not publish,
+// e.g. so that several clients can be published under one name as a map of maps.
+func NewExpvarMetricsMap(vars *expvar.Map) *ExpvarMetrics {
+	return &ExpvarMetrics{
+		vars:      vars,
+		endpoints: make(map[string]*expvar.Map),
+	}
+}
+
+// Map returns the map written to.
+func (m *ExpvarMetrics) Map() *expvar.Map {
+	return m.vars
+}
+
+func (m *ExpvarMetrics) endpoint(endpoint string) *expvar.Map {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.endpoints[endpoint]
+	if !ok {
+		e = new(expvar.Map).Init()
+		buckets := new(expvar.Map).Init()
+		for _, b := range DefaultLatencyBuckets {
+			buckets.Add(strconv.FormatFloat(b, 'g', -1, 64), 0)
+		}
+		buckets.Add("+Inf", 0)
+		e.Set("latency_seconds", buckets)
+		m.endpoints[endpoint] = e
+		m.vars.Set(endpoint, e)
+	}
+	return e
+}
+
+// ObserveCall implements Metrics.
+func (m *ExpvarMetrics) ObserveCall(endpoint string, status int, latency time.Duration, err error) {
+	e := m.endpoint(endpoint)
+	e.Add("requests", 1)
+	e.Add("status_"+statusClass(status), 1)
+
+	seconds := latency.Seconds()
+	buckets := e.Get("latency_seconds").(*expvar.Map)
+	for _, b := range DefaultLatencyBuckets {
+		if seconds <= b {
+			buckets.Add(strconv.FormatFloat(b, 'g', -1, 64), 1)
+		}
+	}
+	buckets.Add("+Inf", 1)
+	e.AddFloat("latency_seconds_sum", seconds)
+	e.Add("latency_seconds_count", 1)
+}
+
+// ObserveRetry implements Metrics.
+func (m *ExpvarMetrics) ObserveRetry(endpoint string, status int) {
+	m.endpoint(endpoint).Add("retries", 1)
+}
+
+// ObserveRateLimits implements Metrics.
+func (m *ExpvarMetrics) ObserveRateLimits(r RateLimits) {
+	remaining := new(expvar.Int)
+	remaining.Set(int64(r.Remaining))
+	m.vars.Set("rate_limit_remaining", remaining)
+
+	limit := new(expvar.Int)
+	limit.Set(int64(r.Limit))
+	m.vars.Set("rate_limit_limit", limit)
+}
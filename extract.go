@@ -17,20 +17,21 @@ limitations under the License.
 package textapi
 
 import (
-	"errors"
-	"net/url"
+	"context"
 )
 
 // ExtractParams is the set of parameters that defines a web page whose data needs to be extracted.
 type ExtractParams struct {
-	// Either URL or HTML is required.
-	URL  string
-	HTML string // Raw HTML of web page
+	// Either Input, URL or HTML is required, see Input.
+	Input Input
+	URL   string
+	HTML  string // Raw HTML of web page
 
 	// Whether to extract the best image of the article.
-	BestImage bool
+	BestImage bool `form:"best_image"`
 
-	Language string
+	// Valid languages are en, de, fr, es, it, pt and auto, see Language.
+	Language Language `form:"language,omitempty,enum=language"`
 }
 
 // ExtractResponse is the JSON description of extract response.
@@ -45,28 +46,19 @@ type ExtractResponse struct {
 
 // Extract extracts information from the web page defined by the given params information.
 func (c *Client) Extract(params *ExtractParams) (*ExtractResponse, error) {
-	body := &url.Values{}
-
-	if len(params.HTML) > 0 {
-		body.Add("html", params.HTML)
-	} else if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else {
-		return nil, errors.New("you must either provide url or html")
-	}
-
-	if len(params.Language) > 0 {
-		body.Add("language", params.Language)
-	}
+	return c.ExtractContext(context.Background(), params)
+}
 
-	if params.BestImage {
-		body.Add("best_image", "true")
-	} else {
-		body.Add("best_image", "false")
+// ExtractContext is like Extract but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) ExtractContext(ctx context.Context, params *ExtractParams, opts ...CallOption) (*ExtractResponse, error) {
+	body, err := c.encode(EndpointExtract, inputOf(params.Input, "", params.HTML, params.URL), params)
+	if err != nil {
+		return nil, err
 	}
 
 	article := &ExtractResponse{}
-	err := c.call("/extract", body, article)
+	err = c.call(ctx, "/extract", body, article, opts...)
 	if err != nil {
 		return nil, err
 	}
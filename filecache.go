@@ -0,0 +1,142 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fileCacheExt is the extension of the files of a FileCache.
+const fileCacheExt = ".cache"
+
+// A FileCache is a Cache storing every entry in its own file of a directory,
+// so that cached results survive restarts and can be shared by processes.
+//
+// Expired entries stay on disk until they are overwritten or removed by Prune.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache returns a FileCache storing its entries in dir, which is created if needed.
+func NewFileCache(dir string) (*FileCache, error) {
+	if len(dir) == 0 {
+		return nil, errors.New("cache directory must not be empty")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+// Get implements Cache.
+func (f *FileCache) Get(key string) ([]byte, bool) {
+	value, expires, ok := f.read(f.path(key))
+	if !ok || (!expires.IsZero() && !time.Now().Before(expires)) {
+		return nil, false
+	}
+	return value, true
+}
+
+// GetStale implements StaleCache.
+func (f *FileCache) GetStale(key string) ([]byte, time.Time, bool) {
+	return f.read(f.path(key))
+}
+
+// Set implements Cache. Entries are written atomically, so concurrent
+// readers never see a partially written entry.
+func (f *FileCache) Set(key string, value []byte, ttl time.Duration) {
+	var expires int64
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).UnixNano()
+	}
+
+	tmp, err := os.CreateTemp(f.dir, "tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(strconv.FormatInt(expires, 10) + "\n")
+	if err == nil {
+		_, err = tmp.Write(value)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+	os.Rename(tmp.Name(), f.path(key))
+}
+
+// Prune removes the expired entries of the cache.
+func (f *FileCache) Prune() error {
+	files, err := filepath.Glob(filepath.Join(f.dir, "*"+fileCacheExt))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, file := range files {
+		_, expires, ok := f.read(file)
+		if ok && (expires.IsZero() || now.Before(expires)) {
+			continue
+		}
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// path returns the path of the file storing the entry of key.
+func (f *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+fileCacheExt)
+}
+
+// read returns the value and expiration time stored in file.
+// A file starts with the expiration time in Unix nanoseconds, or 0, on its own line.
+func (f *FileCache) read(file string) ([]byte, time.Time, bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return nil, time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(strings.TrimSpace(string(data[:i])), 10, 64)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+
+	var expires time.Time
+	if nanos > 0 {
+		expires = time.Unix(0, nanos)
+	}
+	return data[i+1:], expires, true
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// An enum is the set of values accepted by the fields with its enum option.
+type enum struct {
+	// label names the value in error messages, e.g. sentiment mode.
+	label  string
+	values []string
+}
+
+// enums are the enums of the enum options, by name.
+var enums = map[string]enum{
+	"combined_endpoint": {"endpoint", combinedEndpoints},
+	"language":          {"language", languages},
+	"sentiment_mode":    {"sentiment mode", sentimentModes},
+	"summarize_mode":    {"summarize mode", summarizeModes},
+	"taxonomy":          {"taxonomy", taxonomies},
+}
+
+// A formField is the description of a params field given by its form tag.
+type formField struct {
+	name      string
+	omitEmpty bool
+	required  bool
+	path      bool
+	unique    bool
+	def       string
+	enum      string
+	label     string
+	min, max  *int
+}
+
+// parseFormTag parses the form tag of a field, which is its form name
+// followed by comma separated options:
+//
+//	omitempty      the field is not sent when it is zero
+//	required       the field must not be zero
+//	path           the field is part of the endpoint path: it is checked but not sent
+//	unique         the values of a slice field must be distinct
+//	default=value  the value sent when the field is zero
+//	enum=name      the field, or each of its values, must be one of the values of enums[name]
+//	min=n, max=n   the bounds of an int field, or of the number of values of a slice field
+//	label=name     what the values of a slice field are called in errors, its form name by default
+//
+// An unknown option panics, since it is a mistake in the params type.
+func parseFormTag(tag string) formField {
+	options := strings.Split(tag, ",")
+	f := formField{name: options[0]}
+	for _, option := range options[1:] {
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "omitempty":
+			f.omitEmpty = true
+		case "required":
+			f.required = true
+		case "path":
+			f.path = true
+		case "unique":
+			f.unique = true
+		case "default":
+			f.def = value
+		case "enum":
+			if _, ok := enums[value]; !ok {
+				panic(fmt.Sprintf("textapi: unknown enum %q in form tag %q", value, tag))
+			}
+			f.enum = value
+		case "label":
+			f.label = value
+		case "min", "max":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				panic(fmt.Sprintf("textapi: invalid %s in form tag %q", key, tag))
+			}
+			if key == "min" {
+				f.min = &n
+			} else {
+				f.max = &n
+			}
+		default:
+			panic(fmt.Sprintf("textapi: unknown option %q in form tag %q", key, tag))
+		}
+	}
+	return f
+}
+
+// encodeForm adds to form the fields of the struct params points to that have a form tag,
+// see parseFormTag. defaults are the values sent for zero fields, by form name,
+// and take precedence over the default options.
+// Supported fields are strings, bools, ints and slices of strings.
+//
+// encodeForm checks every field and returns the errors of all the invalid ones.
+func encodeForm(form url.Values, params interface{}, defaults map[string]string) []error {
+	v := reflect.Indirect(reflect.ValueOf(params))
+	t := v.Type()
+
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup("form")
+		if !ok {
+			continue
+		}
+		f := parseFormTag(tag)
+
+		values, err := f.encode(v.Field(i))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(values) == 0 {
+			if d := defaults[f.name]; len(d) > 0 {
+				values = []string{d}
+			} else if len(f.def) > 0 {
+				values = []string{f.def}
+			}
+		}
+		if !f.path && len(values) > 0 {
+			form[f.name] = append(form[f.name], values...)
+		}
+	}
+	return errs
+}
+
+// encode returns the values of a field described by f, or an error if it is invalid.
+// A zero field has no values when f.omitEmpty is set.
+func (f formField) encode(v reflect.Value) ([]string, error) {
+	if v.Kind() == reflect.Slice {
+		n := v.Len()
+		if f.required && n == 0 {
+			return nil, f.requiredError()
+		}
+		if f.min != nil && n < *f.min {
+			return nil, fmt.Errorf("you must provide at least %s", f.count(*f.min))
+		}
+		if f.max != nil && n > *f.max {
+			return nil, fmt.Errorf("you must provide at most %s", f.count(*f.max))
+		}
+		values := make([]string, n)
+		for i := range values {
+			value, err := f.encodeValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			if f.unique && contains(values[:i], value) {
+				return nil, fmt.Errorf("duplicate %s %q", f.valueName(), value)
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+
+	if v.IsZero() {
+		if f.required {
+			return nil, f.requiredError()
+		}
+		if f.omitEmpty || len(f.def) > 0 {
+			return nil, nil
+		}
+	}
+	value, err := f.encodeValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return []string{value}, nil
+}
+
+// encodeValue returns a value of a field described by f, or an error if it is invalid.
+func (f formField) encodeValue(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		value := v.String()
+		if e, ok := enums[f.enum]; ok && !contains(e.values, value) {
+			return "", fmt.Errorf("invalid %s %q, accepted values are %s", e.label, value, join(e.values))
+		}
+		return value, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int:
+		n := int(v.Int())
+		if f.min != nil && n < *f.min {
+			return "", fmt.Errorf("%s must be at least %d", f.name, *f.min)
+		}
+		if f.max != nil && n > *f.max {
+			return "", fmt.Errorf("%s must be at most %d", f.name, *f.max)
+		}
+		return strconv.Itoa(n), nil
+	}
+	panic(fmt.Sprintf("textapi: unsupported form field %s of kind %s", f.name, v.Kind()))
+}
+
+// numbers are the numbers spelled out in errors.
+var numbers = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten"}
+
+// count returns n values of a slice field described by f, as said in errors,
+// e.g. "two classes" or "12 values for class".
+func (f formField) count(n int) string {
+	number := strconv.Itoa(n)
+	if n >= 0 && n < len(numbers) {
+		number = numbers[n]
+	}
+	if len(f.label) > 0 {
+		return number + " " + f.label
+	}
+	return number + " values for " + f.name
+}
+
+// valueName returns what a value of a field described by f is called in errors.
+func (f formField) valueName() string {
+	if e, ok := enums[f.enum]; ok {
+		return e.label
+	}
+	return f.name
+}
+
+func (f formField) requiredError() error {
+	if e, ok := enums[f.enum]; ok {
+		return fmt.Errorf("you must specify the %s, accepted values are %s", e.label, join(e.values))
+	}
+	return fmt.Errorf("you must provide a %s", f.name)
+}
+
+// encode returns the form of a call to endpoint: in, followed by the form fields of params.
+// errs are the errors found by the caller. If there is any, or if in or a field is invalid,
+// encode returns a *ValidationError listing all of them, without reading in.
+func (c *Client) encode(endpoint Endpoint, in Input, params interface{}, errs ...error) (*url.Values, error) {
+	if err := in.check(endpoint); err != nil {
+		errs = append([]error{err}, errs...)
+	}
+
+	var defaults map[string]string
+	if len(c.defaultLanguage) > 0 {
+		defaults = map[string]string{"language": string(c.defaultLanguage)}
+	}
+	fields := url.Values{}
+	errs = append(errs, encodeForm(fields, params, defaults)...)
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	body := &url.Values{}
+	if err := in.addTo(body, endpoint); err != nil {
+		return nil, err
+	}
+	for name, values := range fields {
+		(*body)[name] = values
+	}
+	return body, nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestEncodeForm(t *testing.T) {
+	for _, test := range []struct {
+		params interface{}
+		form   string
+	}{
+		{&ExtractParams{URL: "http://example.com"}, "best_image=false"},
+		{&ExtractParams{BestImage: true, Language: LanguageGerman}, "best_image=true&language=de"},
+		{&SummarizeParams{}, "mode=default"},
+		{
+			&SummarizeParams{Title: "title", Mode: SummarizeModeShort, NumberOfSentences: 3, PercentageOfSentences: 20},
+			"mode=short&sentences_number=3&sentences_percentage=20",
+		},
+		{&SentimentParams{}, ""},
+		{&SentimentParams{Mode: SentimentModeDocument}, "mode=document"},
+		{&ConceptsParams{Language: LanguageAuto}, "language=auto"},
+		{&UnsupervisedClassifyParams{Classes: []string{"a", "b"}}, "class=a&class=b"},
+		{&ClassifyByTaxonomyParams{Taxonomy: TaxonomyIABQAG}, ""},
+		{&RelatedParams{Phrase: "phrase", Count: 5}, "count=5&phrase=phrase"},
+		{&CombinedParams{Endpoints: []Endpoint{EndpointSentiment, EndpointEntities}}, "endpoint=sentiment&endpoint=entities"},
+		{&EntitiesParams{Text: "text"}, ""},
+	} {
+		form := url.Values{}
+		if errs := encodeForm(form, test.params, nil); len(errs) > 0 {
+			t.Errorf("unexpected errors %v for %+v", errs, test.params)
+		}
+		if form.Encode() != test.form {
+			t.Errorf("expected form %q for %+v, got %q", test.form, test.params, form.Encode())
+		}
+	}
+}
+
+func TestEncodeFormErrors(t *testing.T) {
+	for _, test := range []struct {
+		params interface{}
+		errs   []string
+	}{
+		{&RelatedParams{Count: -1}, []string{"count must be at least 1", "you must provide a phrase"}},
+		{
+			&SummarizeParams{Mode: "long", PercentageOfSentences: 120},
+			[]string{`invalid summarize mode "long", accepted values are default and short`, "sentences_percentage must be at most 100"},
+		},
+		{&UnsupervisedClassifyParams{Classes: []string{"a"}}, []string{"you must provide at least two classes"}},
+		{&CombinedParams{Endpoints: []Endpoint{EndpointSentiment}}, []string{"you must provide at least two endpoints"}},
+		{
+			&CombinedParams{Endpoints: []Endpoint{EndpointSentiment, EndpointSentiment}},
+			[]string{`duplicate endpoint "sentiment"`},
+		},
+		{&ClassifyByTaxonomyParams{}, []string{"you must specify the taxonomy, accepted values are iab-qag and iptc-subjectcode"}},
+		{
+			&CombinedParams{Endpoints: []Endpoint{EndpointSentiment, "image-tags"}},
+			[]string{`invalid endpoint "image-tags", accepted values are extract, summarize, concepts, entities, hashtags, language, sentiment and classify`},
+		},
+	} {
+		errs := encodeForm(url.Values{}, test.params, nil)
+		if len(errs) != len(test.errs) {
+			t.Errorf("expected errors %q for %+v, got %v", test.errs, test.params, errs)
+			continue
+		}
+		for i, err := range errs {
+			if err.Error() != test.errs[i] {
+				t.Errorf("expected error %q, got %q", test.errs[i], err)
+			}
+		}
+	}
+}
+
+func TestEncodeFormDefaults(t *testing.T) {
+	form := url.Values{}
+	encodeForm(form, &HashtagsParams{}, map[string]string{"language": "fr"})
+	if form.Encode() != "language=fr" {
+		t.Errorf("default not sent: %q", form.Encode())
+	}
+
+	form = url.Values{}
+	encodeForm(form, &HashtagsParams{Language: LanguageSpanish}, map[string]string{"language": "fr"})
+	if form.Encode() != "language=es" {
+		t.Errorf("default overrides the field: %q", form.Encode())
+	}
+}
+
+func TestParseFormTagPanics(t *testing.T) {
+	for _, tag := range []string{"name,omitnil", "name,enum=colors", "name,min=one"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for tag %q", tag)
+				}
+			}()
+			parseFormTag(tag)
+		}()
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	c, _ := newFormRecorder()
+
+	_, err := c.Summarize(&SummarizeParams{Input: TextInput("text"), Mode: "long"})
+	var v *ValidationError
+	if !errors.As(err, &v) || len(v.Errors) != 2 {
+		t.Fatalf("expected a validation error with 2 errors, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Error("validation error must match ErrInvalidInput")
+	}
+	if err.Error() != `you must provide a title with a text; invalid summarize mode "long", accepted values are default and short` {
+		t.Errorf("unexpected message %q", err)
+	}
+}
@@ -17,19 +17,19 @@ limitations under the License.
 package textapi
 
 import (
-	"errors"
-	"net/url"
+	"context"
 )
 
 // HashtagsParams is the set of parameters that defines a document whose hashtags need to be calculated.
 type HashtagsParams struct {
-	// Either URL or Text is required.
-	URL  string
-	Text string
+	// Either Input, URL or Text is required, see Input.
+	Input Input
+	URL   string
+	Text  string
 
-	// Valid languages are en, de, fr, es, it, pt and auto.
+	// Valid languages are en, de, fr, es, it, pt and auto, see Language.
 	// Default is en.
-	Language string
+	Language Language `form:"language,omitempty,enum=language"`
 }
 
 // HashtagsResponse is the JSON description of a hashtags in a document.
@@ -41,22 +41,19 @@ type HashtagsResponse struct {
 
 // Hashtags calculates best hashtags describing the document defined by the given params information.
 func (c *Client) Hashtags(params *HashtagsParams) (*HashtagsResponse, error) {
-	body := &url.Values{}
-
-	if len(params.Text) > 0 {
-		body.Add("text", params.Text)
-	} else if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else {
-		return nil, errors.New("you must either provide url or text")
-	}
+	return c.HashtagsContext(context.Background(), params)
+}
 
-	if len(params.Language) > 0 {
-		body.Add("language", params.Language)
+// HashtagsContext is like Hashtags but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) HashtagsContext(ctx context.Context, params *HashtagsParams, opts ...CallOption) (*HashtagsResponse, error) {
+	body, err := c.encode(EndpointHashtags, inputOf(params.Input, params.Text, "", params.URL), params)
+	if err != nil {
+		return nil, err
 	}
 
 	hashtags := &HashtagsResponse{}
-	err := c.call("/hashtags", body, hashtags)
+	err = c.call(ctx, "/hashtags", body, hashtags, opts...)
 	if err != nil {
 		return nil, err
 	}
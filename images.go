@@ -17,13 +17,14 @@ limitations under the License.
 package textapi
 
 import (
-	"errors"
-	"net/url"
+	"context"
 )
 
 // ImageTagsParams defines the image whose tags needs to be calculated.
 type ImageTagsParams struct {
-	URL string
+	// Either Input or URL is required, see Input.
+	Input Input
+	URL   string
 }
 
 // ImageTag is the JSON description of a pair of tag and confidence
@@ -40,16 +41,19 @@ type ImageTagsResponse struct {
 
 // TagImage tags image defined by the given params information.
 func (c *Client) ImageTags(params *ImageTagsParams) (*ImageTagsResponse, error) {
-	body := &url.Values{}
+	return c.ImageTagsContext(context.Background(), params)
+}
 
-	if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else {
-		return nil, errors.New("you must provide a url")
+// ImageTagsContext is like ImageTags but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) ImageTagsContext(ctx context.Context, params *ImageTagsParams, opts ...CallOption) (*ImageTagsResponse, error) {
+	body, err := c.encode(EndpointImageTags, inputOf(params.Input, "", "", params.URL), params)
+	if err != nil {
+		return nil, err
 	}
 
 	imageTags := &ImageTagsResponse{}
-	err := c.call("image-tags", body, imageTags)
+	err = c.call(ctx, "image-tags", body, imageTags, opts...)
 	if err != nil {
 		return nil, err
 	}
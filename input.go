@@ -0,0 +1,206 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// inputKind is the kind of document an Input holds, which decides its form field.
+type inputKind int
+
+const (
+	inputNone inputKind = iota
+	inputURL
+	inputText
+	inputHTML
+)
+
+func (k inputKind) String() string {
+	switch k {
+	case inputURL:
+		return "url"
+	case inputText:
+		return "text"
+	case inputHTML:
+		return "html"
+	}
+	return "no"
+}
+
+// endpointInputs are the kinds of input accepted by the endpoints
+// that do not accept both a URL and a text.
+var endpointInputs = map[Endpoint][]inputKind{
+	EndpointExtract:      {inputURL, inputHTML},
+	EndpointMicroformats: {inputURL},
+	EndpointImageTags:    {inputURL},
+}
+
+// defaultInputs are the kinds of input accepted by the other endpoints.
+var defaultInputs = []inputKind{inputURL, inputText}
+
+// An Input is the document analyzed by a call: a text, the URL of a web page
+// or an image, or the raw HTML of a web page.
+//
+// Every params type has an Input field, which takes precedence over its
+// Text, HTML and URL fields. Those are used, in that order, when Input is not set.
+// The zero Input is not set.
+type Input struct {
+	kind   inputKind
+	value  string
+	reader io.Reader
+	path   string
+}
+
+// TextInput returns an Input holding text.
+func TextInput(text string) Input {
+	return Input{kind: inputText, value: text}
+}
+
+// URLInput returns an Input holding the URL of a web page or an image.
+func URLInput(u string) Input {
+	return Input{kind: inputURL, value: u}
+}
+
+// HTMLInput returns an Input holding the raw HTML of a web page.
+func HTMLInput(html string) Input {
+	return Input{kind: inputHTML, value: html}
+}
+
+// ReaderInput returns an Input holding the text read from r.
+// r is read when the input is sent, so the Input can only be used once.
+func ReaderInput(r io.Reader) Input {
+	return Input{kind: inputText, reader: r}
+}
+
+// FileInput returns an Input holding the content of the file at path,
+// read when the input is sent. Files whose extension is .html or .htm
+// hold HTML, other files hold text.
+func FileInput(path string) Input {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return Input{kind: inputHTML, path: path}
+	}
+	return Input{kind: inputText, path: path}
+}
+
+// IsZero returns whether in is not set.
+func (in Input) IsZero() bool {
+	return in.kind == inputNone
+}
+
+// inputOf returns in if it is set, and otherwise the first of text, html and u that is set.
+func inputOf(in Input, text, html, u string) Input {
+	switch {
+	case !in.IsZero():
+		return in
+	case len(text) > 0:
+		return TextInput(text)
+	case len(html) > 0:
+		return HTMLInput(html)
+	case len(u) > 0:
+		return URLInput(u)
+	}
+	return Input{}
+}
+
+// check returns an error if in is not set or not accepted by endpoint.
+func (in Input) check(endpoint Endpoint) error {
+	accepted, ok := endpointInputs[endpoint]
+	if !ok {
+		accepted = defaultInputs
+	}
+
+	names := make([]string, len(accepted))
+	for i, k := range accepted {
+		names[i] = k.String()
+		if k == in.kind {
+			return nil
+		}
+	}
+
+	if in.IsZero() {
+		if len(names) == 1 {
+			return fmt.Errorf("you must provide a %s", names[0])
+		}
+		return fmt.Errorf("you must either provide %s", strings.Join(names, " or "))
+	}
+	return fmt.Errorf("%s does not accept %s input, accepted inputs are %s", endpoint, in.kind, join(names))
+}
+
+// checkSet returns an error if in is not set, the one check returns
+// for the endpoints accepting a URL or a text.
+func (in Input) checkSet() error {
+	if !in.IsZero() {
+		return nil
+	}
+	return in.check("")
+}
+
+// read returns the content of in, reading it from its reader or file if needed.
+func (in Input) read() (string, error) {
+	switch {
+	case in.reader != nil:
+		data, err := io.ReadAll(in.reader)
+		if err != nil {
+			return "", fmt.Errorf("reading input: %w", err)
+		}
+		return string(data), nil
+	case len(in.path) > 0:
+		data, err := os.ReadFile(in.path)
+		if err != nil {
+			return "", fmt.Errorf("reading input: %w", err)
+		}
+		return string(data), nil
+	}
+	return in.value, nil
+}
+
+// buffered returns in with its content read, so that it can be sent more than once.
+func (in Input) buffered() (Input, error) {
+	if in.reader == nil && len(in.path) == 0 {
+		return in, nil
+	}
+	value, err := in.read()
+	if err != nil {
+		return Input{}, err
+	}
+	return Input{kind: in.kind, value: value}, nil
+}
+
+// addTo checks that in is accepted by endpoint and adds it to form,
+// as the form field of its kind.
+func (in Input) addTo(form *url.Values, endpoint Endpoint) error {
+	if err := in.check(endpoint); err != nil {
+		return err
+	}
+
+	value, err := in.read()
+	if err != nil {
+		return err
+	}
+	if len(value) == 0 {
+		return fmt.Errorf("%s input is empty", in.kind)
+	}
+	form.Add(in.kind.String(), value)
+	return nil
+}
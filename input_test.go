@@ -0,0 +1,188 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newFormRecorder returns a client answering every call with an empty JSON object,
+// and a function returning the forms it was sent, by path.
+func newFormRecorder() (*Client, func(path string) url.Values) {
+	var mu sync.Mutex
+	forms := map[string]url.Values{}
+	c, _ := NewClient(auth, false, WithTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req.ParseForm()
+		mu.Lock()
+		forms[strings.TrimPrefix(req.URL.Path, "/api/v1")] = req.PostForm
+		mu.Unlock()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		}, nil
+	})))
+	return c, func(path string) url.Values {
+		mu.Lock()
+		defer mu.Unlock()
+		return forms[path]
+	}
+}
+
+func TestInputPrecedence(t *testing.T) {
+	c, form := newFormRecorder()
+
+	c.Sentiment(&SentimentParams{Input: URLInput("http://example.com"), Text: "text"})
+	if f := form("/sentiment"); f.Get("url") != "http://example.com" || f.Has("text") {
+		t.Errorf("Input does not take precedence: %v", f)
+	}
+
+	c.Sentiment(&SentimentParams{Text: "text", URL: "http://example.com"})
+	if f := form("/sentiment"); f.Get("text") != "text" || f.Has("url") {
+		t.Errorf("Text does not take precedence over URL: %v", f)
+	}
+
+	c.Summarize(&SummarizeParams{Text: "text", Title: "title", URL: "http://example.com"})
+	if f := form("/summarize"); f.Get("text") != "text" || f.Get("title") != "title" || f.Has("url") {
+		t.Errorf("Text does not take precedence over URL: %v", f)
+	}
+
+	c.Summarize(&SummarizeParams{Text: "text", URL: "http://example.com"})
+	if f := form("/summarize"); f.Get("url") != "http://example.com" || f.Has("text") {
+		t.Errorf("Text without Title does not fall back to URL: %v", f)
+	}
+
+	c.Summarize(&SummarizeParams{Input: URLInput("http://example.com"), Title: "title"})
+	if f := form("/summarize"); f.Get("url") != "http://example.com" || f.Has("title") {
+		t.Errorf("Title sent with a URL: %v", f)
+	}
+
+	c.Extract(&ExtractParams{HTML: "<p>html</p>", URL: "http://example.com"})
+	if f := form("/extract"); f.Get("html") != "<p>html</p>" || f.Has("url") {
+		t.Errorf("HTML does not take precedence over URL: %v", f)
+	}
+}
+
+func TestInputSources(t *testing.T) {
+	c, form := newFormRecorder()
+
+	c.Concepts(&ConceptsParams{Input: ReaderInput(strings.NewReader("read text"))})
+	if f := form("/concepts"); f.Get("text") != "read text" {
+		t.Errorf("unexpected form %v", f)
+	}
+
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.html")
+	os.WriteFile(page, []byte("<p>page</p>"), 0o600)
+	c.Extract(&ExtractParams{Input: FileInput(page)})
+	if f := form("/extract"); f.Get("html") != "<p>page</p>" {
+		t.Errorf("unexpected form %v", f)
+	}
+
+	article := filepath.Join(dir, "article.txt")
+	os.WriteFile(article, []byte("article"), 0o600)
+	c.Hashtags(&HashtagsParams{Input: FileInput(article)})
+	if f := form("/hashtags"); f.Get("text") != "article" {
+		t.Errorf("unexpected form %v", f)
+	}
+
+	if _, err := c.Hashtags(&HashtagsParams{Input: FileInput(filepath.Join(dir, "missing.txt"))}); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestInputErrors(t *testing.T) {
+	c, _ := newFormRecorder()
+
+	for _, test := range []struct {
+		call func() error
+		err  string
+	}{
+		{
+			func() error { _, err := c.Entities(&EntitiesParams{}); return err },
+			"you must either provide url or text",
+		},
+		{
+			func() error {
+				_, err := c.Analyze(&AnalyzeParams{Endpoints: []Endpoint{EndpointSentiment}})
+				return err
+			},
+			"you must either provide url or text",
+		},
+		{
+			func() error { _, err := c.NewDocument(&DocumentParams{}); return err },
+			"you must either provide url or text",
+		},
+		{
+			func() error { _, err := c.Extract(&ExtractParams{}); return err },
+			"you must either provide url or html",
+		},
+		{
+			func() error { _, err := c.Microformats(&MicroformatsParams{}); return err },
+			"you must provide a url",
+		},
+		{
+			func() error { _, err := c.ImageTags(&ImageTagsParams{Input: TextInput("text")}); return err },
+			"image-tags does not accept text input, accepted inputs are url",
+		},
+		{
+			func() error { _, err := c.Extract(&ExtractParams{Input: TextInput("text")}); return err },
+			"extract does not accept text input, accepted inputs are url and html",
+		},
+		{
+			func() error { _, err := c.Sentiment(&SentimentParams{Input: HTMLInput("<p></p>")}); return err },
+			"sentiment does not accept html input, accepted inputs are url and text",
+		},
+		{
+			func() error { _, err := c.Summarize(&SummarizeParams{Input: TextInput("text")}); return err },
+			"you must provide a title with a text",
+		},
+		{
+			func() error { _, err := c.Language(&LanguageParams{Input: TextInput("")}); return err },
+			"text input is empty",
+		},
+	} {
+		if err := test.call(); err == nil || err.Error() != test.err {
+			t.Errorf("expected error %q, got %v", test.err, err)
+		}
+	}
+}
+
+func TestInputReadOnceByAnalyze(t *testing.T) {
+	c, form := newFormRecorder()
+
+	r, err := c.Analyze(&AnalyzeParams{
+		Input:     ReaderInput(strings.NewReader("read text")),
+		Endpoints: []Endpoint{EndpointSentiment, EndpointClassifyIABQAG},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if form("/sentiment").Get("text") != "read text" || form("/classify/iab-qag").Get("text") != "read text" {
+		t.Error("input not sent with every call")
+	}
+}
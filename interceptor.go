@@ -0,0 +1,81 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// A Call is a single API call as seen by interceptors.
+type Call struct {
+	// Endpoint is the path of the endpoint called, e.g. /sentiment or /classify/iab-qag.
+	Endpoint string
+
+	// Form is the set of form values sent to the endpoint.
+	Form url.Values
+
+	// Header is added to the headers of every request sent for the call.
+	// It cannot override the authentication headers.
+	Header http.Header
+
+	// Result is the pointer the response is decoded into,
+	// e.g. a *SentimentResponse for the /sentiment endpoint.
+	Result interface{}
+
+	// Response is the metadata of the call, filled in when the call is sent.
+	Response *Response
+
+	// body is the raw body of the last response, if the client keeps it.
+	body []byte
+
+	// options are the call options given to the client method.
+	options callOptions
+}
+
+// An Invoker performs a call.
+type Invoker func(ctx context.Context, call *Call) error
+
+// An Interceptor is invoked around every call made by a client.
+//
+// An interceptor can inspect or rewrite the call before passing it to next,
+// and inspect call.Result or the returned error afterwards. It can also
+// short-circuit the call by filling call.Result itself without calling next,
+// for example to answer from a cache.
+type Interceptor func(ctx context.Context, call *Call, next Invoker) error
+
+// WithInterceptors adds interceptors around the calls made by the client.
+// Interceptors run in the given order, the first one being the outermost,
+// after those added by previous WithInterceptors options.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(c *Client) error {
+		c.interceptors = append(c.interceptors, interceptors...)
+		return nil
+	}
+}
+
+// chainInterceptors returns an Invoker that runs interceptors in order around invoker.
+func chainInterceptors(interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, call *Call) error {
+			return interceptor(ctx, call, next)
+		}
+	}
+	return invoker
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+func TestInterceptorsOrder(t *testing.T) {
+	var trace []string
+	record := func(name string) Interceptor {
+		return func(ctx context.Context, call *Call, next Invoker) error {
+			trace = append(trace, name+" "+call.Endpoint)
+			err := next(ctx, call)
+			trace = append(trace, name+" done")
+			return err
+		}
+	}
+
+	c, _ := NewClient(auth, false, WithBaseURL(testServerURL),
+		WithInterceptors(record("first"), record("second")),
+		WithInterceptors(record("third")))
+	if _, err := c.Hashtags(&HashtagsParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "first /hashtags,second /hashtags,third /hashtags,third done,second done,first done"
+	if got := strings.Join(trace, ","); got != expected {
+		t.Errorf("unexpected trace %s", got)
+	}
+}
+
+func TestInterceptorRewritesRequest(t *testing.T) {
+	var requestID, text string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = r.Header.Get("X-Request-ID")
+		text = r.FormValue("text")
+		fmt.Fprintln(w, `{"polarity": "neutral"}`)
+	}))
+	defer server.Close()
+
+	addRequestID := func(ctx context.Context, call *Call, next Invoker) error {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			call.Header.Set("X-Request-ID", id)
+		}
+		call.Header.Set("X-AYLIEN-TextAPI-Application-Key", "overridden")
+		call.Form.Set("text", strings.ToLower(call.Form.Get("text")))
+		return next(ctx, call)
+	}
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithInterceptors(addRequestID))
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "42")
+	var res Response
+	sentiment, err := c.SentimentContext(ctx, &SentimentParams{Text: "LOUD"}, CaptureResponse(&res))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sentiment.Polarity != "neutral" || requestID != "42" || text != "loud" {
+		t.Errorf("request not rewritten: %q %q", requestID, text)
+	}
+	if res.RequestHeader.Get("X-AYLIEN-TextAPI-Application-Key") != redactedKey {
+		t.Error("interceptors must not override authentication headers")
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	cached := func(ctx context.Context, call *Call, next Invoker) error {
+		if sentiment, ok := call.Result.(*SentimentResponse); ok && call.Form.Get("text") == "cached" {
+			sentiment.Polarity = "positive"
+			return nil
+		}
+		return next(ctx, call)
+	}
+
+	var results []interface{}
+	var errs []error
+	observe := func(ctx context.Context, call *Call, next Invoker) error {
+		err := next(ctx, call)
+		results = append(results, call.Result)
+		errs = append(errs, err)
+		return err
+	}
+
+	c, _ := NewClient(auth, false, WithBaseURL("http://127.0.0.1:1"), WithInterceptors(observe, cached))
+	sentiment, err := c.Sentiment(&SentimentParams{Text: "cached"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sentiment.Polarity != "positive" {
+		t.Error("call was not short-circuited")
+	}
+
+	if _, err := c.Sentiment(&SentimentParams{Text: "not cached"}); err == nil {
+		t.Error("did not return error")
+	}
+
+	if len(results) != 2 || results[0] != sentiment || errs[0] != nil || errs[1] == nil {
+		t.Errorf("unexpected observations %v %v", results, errs)
+	}
+}
+
+func TestInterceptorError(t *testing.T) {
+	reject := errors.New("rejected")
+	c, _ := NewClient(auth, false, WithBaseURL(testServerURL), WithInterceptors(
+		func(ctx context.Context, call *Call, next Invoker) error {
+			return reject
+		}))
+	if _, err := c.Concepts(&ConceptsParams{Text: "text"}); err != reject {
+		t.Errorf("expected interceptor error, got %v", err)
+	}
+}
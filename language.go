@@ -17,15 +17,15 @@ limitations under the License.
 package textapi
 
 import (
-	"errors"
-	"net/url"
+	"context"
 )
 
 // LanguageParams is the set of parameters that defines a document whose language needs to be calculated.
 type LanguageParams struct {
-	// Either URL or Text is required.
-	URL  string
-	Text string
+	// Either Input, URL or Text is required, see Input.
+	Input Input
+	URL   string
+	Text  string
 }
 
 // LanguageResponse is the JSON description of language response.
@@ -37,18 +37,19 @@ type LanguageResponse struct {
 
 // Language calculates the language in which the document defined by the given params information is written in.
 func (c *Client) Language(params *LanguageParams) (*LanguageResponse, error) {
-	body := &url.Values{}
-
-	if len(params.Text) > 0 {
-		body.Add("text", params.Text)
-	} else if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else {
-		return nil, errors.New("you must either provide url or text")
+	return c.LanguageContext(context.Background(), params)
+}
+
+// LanguageContext is like Language but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) LanguageContext(ctx context.Context, params *LanguageParams, opts ...CallOption) (*LanguageResponse, error) {
+	body, err := c.encode(EndpointLanguage, inputOf(params.Input, params.Text, "", params.URL), params)
+	if err != nil {
+		return nil, err
 	}
 
 	language := &LanguageResponse{}
-	err := c.call("/language", body, language)
+	err = c.call(ctx, "/language", body, language, opts...)
 	if err != nil {
 		return nil, err
 	}
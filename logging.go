@@ -0,0 +1,136 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// maxLoggedBody is the number of bytes of a body logged at most.
+const maxLoggedBody = 4 << 10
+
+// A LogConfig defines how a client logs its calls.
+// The application key is never logged.
+type LogConfig struct {
+	// Logger receives the log records. It is required.
+	Logger *slog.Logger
+
+	// Level is the level of successful calls. Default is slog.LevelDebug.
+	Level slog.Leveler
+
+	// RetryLevel is the level of failed attempts that are retried.
+	// Default is slog.LevelWarn.
+	RetryLevel slog.Leveler
+
+	// ErrorLevel is the level of failed calls. Default is slog.LevelError.
+	ErrorLevel slog.Leveler
+
+	// LogBodies is whether the form values sent, the request headers and
+	// the response body are logged, truncated to 4 KB each.
+	LogBodies bool
+}
+
+// WithLogger makes the client log its calls to logger using the default LogConfig.
+func WithLogger(logger *slog.Logger) Option {
+	return WithLogConfig(LogConfig{Logger: logger})
+}
+
+// WithLogConfig makes the client log its calls as defined by config.
+//
+// Every call is logged with its endpoint, status, latency, number of
+// attempts and the rate limits of its response.
+func WithLogConfig(config LogConfig) Option {
+	return func(c *Client) error {
+		if config.Logger == nil {
+			return errors.New("logger must not be nil")
+		}
+		if config.Level == nil {
+			config.Level = slog.LevelDebug
+		}
+		if config.RetryLevel == nil {
+			config.RetryLevel = slog.LevelWarn
+		}
+		if config.ErrorLevel == nil {
+			config.ErrorLevel = slog.LevelError
+		}
+
+		c.logger = &logger{config}
+		if config.LogBodies {
+			c.keepBodies = true
+		}
+		return nil
+	}
+}
+
+type logger struct {
+	LogConfig
+}
+
+func (l *logger) logCall(ctx context.Context, call *Call, err error) {
+	level, msg := l.Level.Level(), "text api call"
+	if err != nil {
+		level, msg = l.ErrorLevel.Level(), "text api call failed"
+	}
+	if !l.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	meta := call.Response
+	attrs := []slog.Attr{
+		slog.String("endpoint", call.Endpoint),
+		slog.Int("status", meta.StatusCode),
+		slog.Duration("latency", meta.Latency),
+		slog.Int("attempts", meta.Attempts),
+		slog.Group("rate_limit",
+			slog.Int("limit", meta.RateLimits.Limit),
+			slog.Int("remaining", meta.RateLimits.Remaining),
+			slog.Time("reset", meta.RateLimits.ResetTime()),
+		),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	if l.LogBodies {
+		attrs = append(attrs,
+			slog.String("form", truncate(call.Form.Encode())),
+			slog.Any("request_header", meta.RequestHeader),
+			slog.String("response_body", truncate(string(call.body))),
+		)
+	}
+
+	l.Logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func (l *logger) logRetry(ctx context.Context, call *Call, attempt int, err error, wait time.Duration) {
+	l.Logger.LogAttrs(ctx, l.RetryLevel.Level(), "retrying text api call",
+		slog.String("endpoint", call.Endpoint),
+		slog.Int("status", call.Response.StatusCode),
+		slog.Int("attempt", attempt),
+		slog.Duration("wait", wait),
+		slog.Any("error", err),
+	)
+}
+
+func truncate(s string) string {
+	if len(s) > maxLoggedBody {
+		return s[:maxLoggedBody] + "..."
+	}
+	return s
+}
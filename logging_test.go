@@ -0,0 +1,125 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeLogRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var record map[string]interface{}
+		if err := dec.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogging(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	c, _ := NewClient(auth, false, WithBaseURL(testServerURL), WithLogger(logger))
+	if _, err := c.Language(&LanguageParams{Text: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Entities(&EntitiesParams{URL: "invalid"}); err == nil {
+		t.Error("did not return error")
+	}
+
+	records := decodeLogRecords(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0]["level"] != "DEBUG" || records[0]["endpoint"] != "/language" ||
+		records[0]["status"] != 200.0 || records[0]["attempts"] != 1.0 {
+		t.Errorf("unexpected record %v", records[0])
+	}
+	if limits, ok := records[0]["rate_limit"].(map[string]interface{}); !ok || limits["remaining"] != 999.0 {
+		t.Errorf("unexpected rate limits %v", records[0]["rate_limit"])
+	}
+	if records[1]["level"] != "ERROR" || records[1]["status"] != 400.0 || records[1]["error"] == nil {
+		t.Errorf("unexpected record %v", records[1])
+	}
+	if _, ok := records[0]["response_body"]; ok {
+		t.Error("bodies must not be logged by default")
+	}
+}
+
+func TestLoggingRetriesAndBodies(t *testing.T) {
+	server, _ := newFlakyServer(1, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	secret := Auth{"app", "secret-key"}
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	c, _ := NewClient(secret, false, WithBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MinBackoff: time.Millisecond}),
+		WithLogConfig(LogConfig{Logger: logger, Level: slog.LevelInfo, LogBodies: true}))
+	if _, err := c.Sentiment(&SentimentParams{Text: "some text"}); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("auth", "auth", secret)
+
+	if strings.Contains(buf.String(), "secret-key") {
+		t.Fatalf("application key was logged: %s", buf.String())
+	}
+
+	records := decodeLogRecords(t, buf)
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if records[0]["level"] != "WARN" || records[0]["attempt"] != 1.0 || records[0]["status"] != 503.0 {
+		t.Errorf("unexpected retry record %v", records[0])
+	}
+	if records[1]["level"] != "INFO" || records[1]["attempts"] != 2.0 ||
+		records[1]["form"] != "text=some+text" || !strings.Contains(records[1]["response_body"].(string), "positive") {
+		t.Errorf("unexpected record %v", records[1])
+	}
+	if header, ok := records[1]["request_header"].(map[string]interface{}); !ok ||
+		fmt.Sprint(header["X-Aylien-Textapi-Application-Key"]) != "["+redactedKey+"]" {
+		t.Errorf("unexpected request header %v", records[1]["request_header"])
+	}
+
+	if _, err := NewClient(auth, false, WithLogger(nil)); err == nil {
+		t.Error("did not return error")
+	}
+}
+
+func TestAuthRedacted(t *testing.T) {
+	secret := Auth{"app", "secret-key"}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%d"} {
+		out := fmt.Sprintf(format, secret)
+		if strings.Contains(out, "secret-key") || !strings.Contains(out, "app") {
+			t.Errorf("%s: %s", format, out)
+		}
+	}
+	if out := fmt.Sprintf("%v", &secret); strings.Contains(out, "secret-key") {
+		t.Errorf("pointer: %s", out)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Metrics records the activity of a client.
+// Endpoints are the paths actually called, e.g. /sentiment, /combined
+// or /classify/iab-qag. Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveCall records a call once it returns, after any retries.
+	// status is the HTTP status of the last attempt, or 0 if no response was received.
+	ObserveCall(endpoint string, status int, latency time.Duration, err error)
+
+	// ObserveRetry records a failed attempt that is about to be retried.
+	ObserveRetry(endpoint string, status int)
+
+	// ObserveRateLimits records the rate limits sent with a response.
+	ObserveRateLimits(r RateLimits)
+}
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the latency
+// histograms of ExpvarMetrics and PrometheusMetrics.
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// WithMetrics makes the client record its activity in m.
+func WithMetrics(m Metrics) Option {
+	return func(c *Client) error {
+		if m == nil {
+			return errors.New("metrics must not be nil")
+		}
+		c.metrics = m
+		return nil
+	}
+}
+
+// statusClass returns the class of status, e.g. 2xx, or error if no response was received.
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "error"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
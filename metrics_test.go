@@ -0,0 +1,137 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func callEveryKind(t *testing.T, c *Client) {
+	if _, err := c.Combined(&CombinedParams{Text: "text", Endpoints: []Endpoint{"sentiment", "entities"}}); err != nil {
+		t.Fatal(err)
+	}
+	params := &ClassifyByTaxonomyParams{Text: "text", Taxonomy: "iab-qag"}
+	if _, err := c.ClassifyByTaxonomy(params); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Language(&LanguageParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Entities(&EntitiesParams{URL: "invalid"}); err == nil {
+		t.Fatal("did not return error")
+	}
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	m := NewPrometheusMetrics()
+	c, _ := NewClient(auth, false, WithBaseURL(testServerURL), WithMetrics(m))
+	callEveryKind(t, c)
+
+	buf := &bytes.Buffer{}
+	n, err := m.WriteTo(buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("WriteTo returned %d, %v", n, err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		`textapi_requests_total{endpoint="/combined",status_class="2xx"} 1`,
+		`textapi_requests_total{endpoint="/classify/iab-qag",status_class="2xx"} 1`,
+		`textapi_requests_total{endpoint="/entities",status_class="4xx"} 1`,
+		`textapi_request_duration_seconds_bucket{endpoint="/language",le="+Inf"} 1`,
+		`textapi_request_duration_seconds_count{endpoint="/language"} 1`,
+		`textapi_retries_total{endpoint="/language"} 0`,
+		`textapi_rate_limit_remaining 999`,
+		`textapi_rate_limit_limit 1000`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing %s in:\n%s", line, out)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") || rec.Body.String() != out {
+		t.Error("unexpected metrics page")
+	}
+}
+
+func TestPrometheusRetries(t *testing.T) {
+	server, _ := newFlakyServer(2, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	m := NewPrometheusMetrics()
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithMetrics(m),
+		WithRetryPolicy(RetryPolicy{MinBackoff: time.Millisecond}))
+	if _, err := c.Sentiment(&SentimentParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	m.WriteTo(buf)
+	if !strings.Contains(buf.String(), `textapi_retries_total{endpoint="/sentiment"} 2`+"\n") {
+		t.Errorf("retries not recorded:\n%s", buf.String())
+	}
+}
+
+// expvarNames counts the expvar names published by tests, which must be unique
+// for the tests to run more than once in a process, e.g. with -count.
+var expvarNames int64
+
+func TestExpvarMetrics(t *testing.T) {
+	m := NewExpvarMetricsMap(new(expvar.Map).Init())
+	c, _ := NewClient(auth, false, WithBaseURL(testServerURL), WithMetrics(m))
+	callEveryKind(t, c)
+
+	taxonomy, ok := m.Map().Get("/classify/iab-qag").(*expvar.Map)
+	if !ok || taxonomy.Get("requests").String() != "1" || taxonomy.Get("status_2xx").String() != "1" {
+		t.Errorf("unexpected metrics %v", m.Map())
+	}
+	entities, ok := m.Map().Get("/entities").(*expvar.Map)
+	if !ok || entities.Get("status_4xx").String() != "1" {
+		t.Fatalf("unexpected metrics %v", m.Map())
+	}
+	latency, ok := entities.Get("latency_seconds").(*expvar.Map)
+	if !ok || latency.Get("+Inf").String() != "1" || entities.Get("latency_seconds_count").String() != "1" {
+		t.Errorf("unexpected latency %v", entities)
+	}
+	if m.Map().Get("rate_limit_remaining").String() != "999" {
+		t.Errorf("unexpected rate limits %v", m.Map())
+	}
+}
+
+func TestExpvarMetricsPublished(t *testing.T) {
+	name := fmt.Sprintf("textapi_test_%d", atomic.AddInt64(&expvarNames, 1))
+	m := NewExpvarMetrics(name)
+	if expvar.Get(name) != m.Map() {
+		t.Fatal("metrics not published")
+	}
+	c, _ := NewClient(auth, false, WithBaseURL(testServerURL), WithMetrics(m))
+	if _, err := c.Language(&LanguageParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	if language, ok := m.Map().Get("/language").(*expvar.Map); !ok || language.Get("requests").String() != "1" {
+		t.Errorf("unexpected metrics %v", m.Map())
+	}
+}
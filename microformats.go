@@ -17,13 +17,14 @@ limitations under the License.
 package textapi
 
 import (
-	"errors"
-	"net/url"
+	"context"
 )
 
 // MicroformatsParams is the set of parameters that defines a document whose microformats needs to be extracted.
 type MicroformatsParams struct {
-	URL string
+	// Either Input or URL is required, see Input.
+	Input Input
+	URL   string
 }
 
 // An Address is the JSON description of an hCard adr
@@ -79,16 +80,19 @@ type MicroformatsResponse struct {
 
 // Microformats extracts microformats from document defined by the given params information.
 func (c *Client) Microformats(params *MicroformatsParams) (*MicroformatsResponse, error) {
-	body := &url.Values{}
+	return c.MicroformatsContext(context.Background(), params)
+}
 
-	if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else {
-		return nil, errors.New("you must provide a url")
+// MicroformatsContext is like Microformats but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) MicroformatsContext(ctx context.Context, params *MicroformatsParams, opts ...CallOption) (*MicroformatsResponse, error) {
+	body, err := c.encode(EndpointMicroformats, inputOf(params.Input, "", "", params.URL), params)
+	if err != nil {
+		return nil, err
 	}
 
 	microformats := &MicroformatsResponse{}
-	err := c.call("/microformats", body, microformats)
+	err = c.call(ctx, "/microformats", body, microformats, opts...)
 	if err != nil {
 		return nil, err
 	}
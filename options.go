@@ -0,0 +1,136 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// An Option configures a Client. Options are passed to NewClient.
+type Option func(*Client) error
+
+// WithHTTPClient makes the client send its requests through httpClient.
+// The given client is never modified; WithTransport applies to a copy of it.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) error {
+		if httpClient == nil {
+			return errors.New("http client must not be nil")
+		}
+		c.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithTransport makes the client send its requests through transport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) error {
+		if transport == nil {
+			return errors.New("transport must not be nil")
+		}
+		c.transport = transport
+		return nil
+	}
+}
+
+// WithTimeout sets a default timeout for every API call.
+// A shorter deadline carried by the context of a call still applies.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout < 0 {
+			return errors.New("timeout must not be negative")
+		}
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithBaseURL makes the client call the Text API at baseURL,
+// e.g. an on-premise gateway, a staging environment or a test server.
+// The scheme of baseURL takes precedence over the useHTTPS argument of NewClient.
+// If baseURL has no scheme, useHTTPS decides which one is used.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) error {
+		u, err := parseBaseURL(baseURL, c.useHTTPS)
+		if err != nil {
+			return err
+		}
+		c.baseURL = u
+		return nil
+	}
+}
+
+// parseBaseURL validates baseURL and returns it without trailing slash.
+func parseBaseURL(baseURL string, useHTTPS bool) (string, error) {
+	if !strings.Contains(baseURL, "://") {
+		protocol := "http"
+		if useHTTPS {
+			protocol = "https"
+		}
+		baseURL = protocol + "://" + baseURL
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", errors.New("base URL scheme must be http or https")
+	}
+	if len(u.Host) == 0 {
+		return "", errors.New("base URL must have a host")
+	}
+
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+// WithUserAgent appends suffix to the User-Agent header sent with every request.
+func WithUserAgent(suffix string) Option {
+	return func(c *Client) error {
+		if len(suffix) > 0 {
+			c.userAgent += " " + suffix
+		}
+		return nil
+	}
+}
+
+// WithMaxResponseSize sets the largest response body, in bytes, the client accepts.
+// Larger responses fail with ErrResponseTooLarge.
+func WithMaxResponseSize(size int64) Option {
+	return func(c *Client) error {
+		if size <= 0 {
+			return errors.New("max response size must be positive")
+		}
+		c.maxResponseSize = size
+		return nil
+	}
+}
+
+// WithDefaultLanguage sets the language sent to the endpoints that accept one
+// when the params of a call do not specify it.
+func WithDefaultLanguage(language Language) Option {
+	return func(c *Client) error {
+		if err := validate("language", string(language), languages); err != nil {
+			return err
+		}
+		c.defaultLanguage = language
+		return nil
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestDefaultBaseURL(t *testing.T) {
+	c, _ := NewClient(auth, true)
+	if c.baseURL != "https://api.aylien.com/api/v1" {
+		t.Errorf("unexpected base URL %q", c.baseURL)
+	}
+	c, _ = NewClient(auth, false)
+	if c.baseURL != "http://api.aylien.com/api/v1" {
+		t.Errorf("unexpected base URL %q", c.baseURL)
+	}
+}
+
+func TestWithBaseURL(t *testing.T) {
+	var path, userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		userAgent = r.Header.Get("User-Agent")
+		fmt.Fprintln(w, "{}")
+	}))
+	defer server.Close()
+
+	c, err := NewClient(auth, true, WithBaseURL(server.URL+"/gateway/v1/"), WithUserAgent("batch-job/1.2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Sentiment(&SentimentParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/gateway/v1/sentiment" {
+		t.Errorf("unexpected path %q", path)
+	}
+	if userAgent != "Aylien Text API Go "+version+" batch-job/1.2" {
+		t.Errorf("unexpected user agent %q", userAgent)
+	}
+
+	for _, baseURL := range []string{"ftp://example.com", "http://", "http://%zz"} {
+		if _, err := NewClient(auth, true, WithBaseURL(baseURL)); err == nil {
+			t.Errorf("%q: did not return error", baseURL)
+		}
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	var host string
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		host = req.URL.Host
+		return nil, errors.New("transport called")
+	})
+
+	httpClient := &http.Client{}
+	c, err := NewClient(auth, true, WithHTTPClient(httpClient), WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Language(&LanguageParams{Text: "text"}); err == nil {
+		t.Error("did not return error")
+	}
+	if host != "api.aylien.com" {
+		t.Errorf("unexpected host %q", host)
+	}
+	if httpClient.Transport != nil {
+		t.Error("given http client must not be modified")
+	}
+
+	if _, err := NewClient(auth, true, WithHTTPClient(nil)); err == nil {
+		t.Error("did not return error")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithTimeout(50*time.Millisecond))
+	_, err := c.Hashtags(&HashtagsParams{Text: "text"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
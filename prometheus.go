@@ -0,0 +1,193 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PrometheusMetrics is a Metrics that keeps the activity of a client in memory
+// and writes it in the Prometheus text exposition format. It exposes:
+//
+//	textapi_requests_total{endpoint,status_class}
+//	textapi_request_duration_seconds{endpoint} (histogram)
+//	textapi_retries_total{endpoint}
+//	textapi_rate_limit_remaining
+//	textapi_rate_limit_limit
+type PrometheusMetrics struct {
+	mu        sync.Mutex
+	endpoints map[string]*endpointMetrics
+	limits    RateLimits
+	hasLimits bool
+}
+
+type endpointMetrics struct {
+	requests map[string]uint64 // by status class
+	retries  uint64
+	buckets  []uint64 // cumulative, one per DefaultLatencyBuckets
+	count    uint64
+	sum      float64
+}
+
+// NewPrometheusMetrics returns an empty PrometheusMetrics.
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{endpoints: make(map[string]*endpointMetrics)}
+}
+
+func (m *PrometheusMetrics) endpoint(endpoint string) *endpointMetrics {
+	e, ok := m.endpoints[endpoint]
+	if !ok {
+		e = &endpointMetrics{
+			requests: make(map[string]uint64),
+			buckets:  make([]uint64, len(DefaultLatencyBuckets)),
+		}
+		m.endpoints[endpoint] = e
+	}
+	return e
+}
+
+// ObserveCall implements Metrics.
+func (m *PrometheusMetrics) ObserveCall(endpoint string, status int, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.endpoint(endpoint)
+	e.requests[statusClass(status)]++
+
+	seconds := latency.Seconds()
+	for i, b := range DefaultLatencyBuckets {
+		if seconds <= b {
+			e.buckets[i]++
+		}
+	}
+	e.count++
+	e.sum += seconds
+}
+
+// ObserveRetry implements Metrics.
+func (m *PrometheusMetrics) ObserveRetry(endpoint string, status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.endpoint(endpoint).retries++
+}
+
+// ObserveRateLimits implements Metrics.
+func (m *PrometheusMetrics) ObserveRateLimits(r RateLimits) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limits = r
+	m.hasLimits = true
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	endpoints := make([]string, 0, len(m.endpoints))
+	for endpoint := range m.endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	fmt.Fprintln(cw, "# HELP textapi_requests_total Text API calls by endpoint and status class.")
+	fmt.Fprintln(cw, "# TYPE textapi_requests_total counter")
+	for _, endpoint := range endpoints {
+		e := m.endpoints[endpoint]
+		classes := make([]string, 0, len(e.requests))
+		for class := range e.requests {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		for _, class := range classes {
+			fmt.Fprintf(cw, "textapi_requests_total{endpoint=%s,status_class=%s} %d\n",
+				quoteLabel(endpoint), quoteLabel(class), e.requests[class])
+		}
+	}
+
+	fmt.Fprintln(cw, "# HELP textapi_request_duration_seconds Latency of Text API calls, including retries.")
+	fmt.Fprintln(cw, "# TYPE textapi_request_duration_seconds histogram")
+	for _, endpoint := range endpoints {
+		e := m.endpoints[endpoint]
+		label := quoteLabel(endpoint)
+		for i, b := range DefaultLatencyBuckets {
+			fmt.Fprintf(cw, "textapi_request_duration_seconds_bucket{endpoint=%s,le=%s} %d\n",
+				label, quoteLabel(strconv.FormatFloat(b, 'g', -1, 64)), e.buckets[i])
+		}
+		fmt.Fprintf(cw, "textapi_request_duration_seconds_bucket{endpoint=%s,le=\"+Inf\"} %d\n", label, e.count)
+		fmt.Fprintf(cw, "textapi_request_duration_seconds_sum{endpoint=%s} %s\n", label, strconv.FormatFloat(e.sum, 'g', -1, 64))
+		fmt.Fprintf(cw, "textapi_request_duration_seconds_count{endpoint=%s} %d\n", label, e.count)
+	}
+
+	fmt.Fprintln(cw, "# HELP textapi_retries_total Retried Text API attempts.")
+	fmt.Fprintln(cw, "# TYPE textapi_retries_total counter")
+	for _, endpoint := range endpoints {
+		fmt.Fprintf(cw, "textapi_retries_total{endpoint=%s} %d\n", quoteLabel(endpoint), m.endpoints[endpoint].retries)
+	}
+
+	if m.hasLimits {
+		fmt.Fprintln(cw, "# HELP textapi_rate_limit_remaining Latest X-RateLimit-Remaining sent by the Text API.")
+		fmt.Fprintln(cw, "# TYPE textapi_rate_limit_remaining gauge")
+		fmt.Fprintf(cw, "textapi_rate_limit_remaining %d\n", m.limits.Remaining)
+		fmt.Fprintln(cw, "# HELP textapi_rate_limit_limit Latest X-RateLimit-Limit sent by the Text API.")
+		fmt.Fprintln(cw, "# TYPE textapi_rate_limit_limit gauge")
+		fmt.Fprintf(cw, "textapi_rate_limit_limit %d\n", m.limits.Limit)
+	}
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+func quoteLabel(v string) string {
+	v = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+	return `"` + v + `"`
+}
+
+// A countingWriter counts the bytes written to w and keeps the first error.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WithRateLimit makes the client throttle itself instead of sending
+// requests the API would reject.
+//
+// At most rps requests per second are sent, with bursts of up to burst requests.
+// If rps is zero, there is no such budget. In both cases, once the
+// X-RateLimit-Remaining header of a response reaches zero, further calls
+// block until the time given by X-RateLimit-Reset.
+// Calls whose context would expire before they may be sent fail right away
+// with an error matching ErrRateLimited.
+//
+// The limiter is shared by all goroutines using the client.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) error {
+		if rps < 0 {
+			return errors.New("requests per second must not be negative")
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		c.limiter = &rateLimiter{
+			rps:    rps,
+			burst:  float64(burst),
+			tokens: float64(burst),
+		}
+		return nil
+	}
+}
+
+// A rateLimiter is a token bucket combined with the quota reported by the API.
+type rateLimiter struct {
+	mu sync.Mutex
+
+	rps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	// remaining and reset are the quota reported by the last response,
+	// minus the requests sent since. They are only used if quotaKnown is true.
+	quotaKnown bool
+	remaining  int
+	reset      time.Time
+}
+
+// wait blocks until a request may be sent or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	var until time.Time
+
+	if l.quotaKnown && !now.Before(l.reset) {
+		l.quotaKnown = false
+	}
+	if l.quotaKnown && l.remaining <= 0 {
+		until = l.reset
+	}
+
+	if l.rps > 0 {
+		if !l.last.IsZero() {
+			l.tokens += now.Sub(l.last).Seconds() * l.rps
+			if l.tokens > l.burst {
+				l.tokens = l.burst
+			}
+		}
+		l.last = now
+		l.tokens--
+		if l.tokens < 0 {
+			t := now.Add(time.Duration(-l.tokens / l.rps * float64(time.Second)))
+			if t.After(until) {
+				until = t
+			}
+		}
+	}
+
+	quotaKnown := l.quotaKnown
+	if quotaKnown {
+		l.remaining--
+	}
+	l.mu.Unlock()
+
+	wait := until.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(until) {
+		l.cancel(quotaKnown)
+		return fmt.Errorf("%w: next request allowed at %v", ErrRateLimited, until)
+	}
+	if err := sleep(ctx, wait); err != nil {
+		l.cancel(quotaKnown)
+		return err
+	}
+	return nil
+}
+
+// cancel gives back what wait reserved for a request that was not sent.
+func (l *rateLimiter) cancel(quotaKnown bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rps > 0 {
+		l.tokens++
+	}
+	if quotaKnown && l.quotaKnown {
+		l.remaining++
+	}
+}
+
+// update records the quota reported by a response.
+func (l *rateLimiter) update(r RateLimits) {
+	if r.Limit <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.quotaKnown = true
+	l.remaining = r.Remaining
+	l.reset = r.ResetTime()
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitBudget(t *testing.T) {
+	c, _ := NewClient(auth, false, WithBaseURL(testServerURL), WithRateLimit(50, 1))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Sentiment(&SentimentParams{Text: "text"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("6 calls at 50 rps took only %v", elapsed)
+	}
+}
+
+func TestRateLimitQuota(t *testing.T) {
+	var requests int32
+	reset := time.Now().Add(time.Second).Unix() + 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining := 0
+		if atomic.AddInt32(&requests, 1) > 1 {
+			remaining = 999
+		}
+		w.Header().Add("X-RateLimit-Limit", "1000")
+		w.Header().Add("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Add("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		fmt.Fprintln(w, "{}")
+	}))
+	defer server.Close()
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithRateLimit(0, 1))
+	if _, err := c.Sentiment(&SentimentParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := c.SentimentContext(ctx, &SentimentParams{Text: "text"})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("request must not be sent before reset, got %d requests", requests)
+	}
+
+	if _, err := c.Sentiment(&SentimentParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	if now := time.Now(); now.Before(time.Unix(reset, 0)) {
+		t.Errorf("request sent at %v, before reset", now)
+	}
+}
+
+func TestRateLimitCancel(t *testing.T) {
+	l := &rateLimiter{rps: 1, burst: 1, tokens: 1}
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if l.tokens < -0.01 {
+		t.Errorf("cancelled wait must give its token back, got %v tokens", l.tokens)
+	}
+}
@@ -17,15 +17,14 @@ limitations under the License.
 package textapi
 
 import (
-	"errors"
+	"context"
 	"net/url"
-	"strconv"
 )
 
 // RelatedParams is the set of parameters that defines a phrase whose related phrases needs to be retrieved.
 type RelatedParams struct {
-	Count  int
-	Phrase string
+	Count  int    `form:"count,omitempty,min=1"`
+	Phrase string `form:"phrase,required"`
 }
 
 // Related is the JSON description of a related phrase.
@@ -42,20 +41,19 @@ type RelatedResponse struct {
 
 // Related returns related phrases to the phrase defined by the given params information.
 func (c *Client) Related(params *RelatedParams) (*RelatedResponse, error) {
-	body := &url.Values{}
-
-	if len(params.Phrase) > 0 {
-		body.Add("phrase", params.Phrase)
-	} else {
-		return nil, errors.New("you must provide a phrase")
-	}
+	return c.RelatedContext(context.Background(), params)
+}
 
-	if params.Count > 0 {
-		body.Add("count", strconv.Itoa(params.Count))
+// RelatedContext is like Related but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) RelatedContext(ctx context.Context, params *RelatedParams, opts ...CallOption) (*RelatedResponse, error) {
+	body := &url.Values{}
+	if errs := encodeForm(*body, params, nil); len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
 	}
 
 	related := &RelatedResponse{}
-	err := c.call("/related", body, related)
+	err := c.call(ctx, "/related", body, related, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,81 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"net/http"
+	"time"
+)
+
+// A Response is the metadata of a single API call.
+type Response struct {
+	// StatusCode, Header and RateLimits describe the response to the last attempt.
+	StatusCode int
+	Header     http.Header
+	RateLimits RateLimits
+
+	// RequestHeader is the header sent with the last attempt,
+	// with the application key redacted.
+	RequestHeader http.Header
+
+	// Latency is the duration of the whole call, including retries.
+	Latency time.Duration
+
+	// Attempts is the number of requests sent, including retries.
+	Attempts int
+
+	// Cached is whether the result was read from the cache of the client.
+	// Unless Stale is set, no request was sent and the other fields are zero.
+	Cached bool
+
+	// Stale is whether the result is an expired cached one, returned because
+	// the call failed, see WithStaleIfError. The other fields describe the failed call.
+	Stale bool
+
+	// Shared is whether the call was merged into an identical call
+	// already in flight, see WithDeduplication.
+	Shared bool
+}
+
+// A CallOption configures a single API call.
+// Call options are passed to the Context variants of the Client methods.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	response    *Response
+	bypassCache bool
+}
+
+// CaptureResponse stores the metadata of the call in r once the call returns,
+// whether it succeeded or not.
+func CaptureResponse(r *Response) CallOption {
+	return func(o *callOptions) {
+		o.response = r
+	}
+}
+
+// redactedKey replaces the application key wherever it could be exposed.
+const redactedKey = "REDACTED"
+
+// redactHeader returns a copy of header with the application key redacted.
+func redactHeader(header http.Header) http.Header {
+	h := header.Clone()
+	if len(h.Get("X-AYLIEN-TextAPI-Application-Key")) > 0 {
+		h.Set("X-AYLIEN-TextAPI-Application-Key", redactedKey)
+	}
+	return h
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCaptureResponse(t *testing.T) {
+	var res Response
+	params := &LanguageParams{Text: "John is a very good football player!"}
+	if _, err := client.LanguageContext(context.Background(), params, CaptureResponse(&res)); err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 || res.Attempts != 1 || res.Latency <= 0 {
+		t.Errorf("unexpected response %+v", res)
+	}
+	if res.RateLimits != (RateLimits{Limit: 1000, Remaining: 999, Reset: 1420479141}) {
+		t.Errorf("unexpected rate limits %+v", res.RateLimits)
+	}
+	if !res.RateLimits.ResetTime().Equal(time.Unix(1420479141, 0)) {
+		t.Errorf("unexpected reset time %v", res.RateLimits.ResetTime())
+	}
+	if res.RequestHeader.Get("X-AYLIEN-TextAPI-Application-ID") != "test" ||
+		res.RequestHeader.Get("X-AYLIEN-TextAPI-Application-Key") != redactedKey {
+		t.Errorf("unexpected request header %v", res.RequestHeader)
+	}
+
+	res = Response{}
+	_, err := client.EntitiesContext(context.Background(), &EntitiesParams{URL: "invalid"}, CaptureResponse(&res))
+	if err == nil {
+		t.Error("did not return error")
+	}
+	if res.StatusCode != 400 {
+		t.Errorf("response must be captured on error, got %+v", res)
+	}
+}
+
+func TestLastRateLimitsConcurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Add("X-RateLimit-Limit", "1000")
+		w.Header().Add("X-RateLimit-Remaining", r.FormValue("text"))
+		w.Header().Add("X-RateLimit-Reset", "1420479141")
+		fmt.Fprintln(w, "{}")
+	}))
+	defer server.Close()
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL))
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var res Response
+			text := strconv.Itoa(i)
+			if _, err := c.SentimentContext(context.Background(), &SentimentParams{Text: text}, CaptureResponse(&res)); err != nil {
+				t.Error(err)
+			}
+			if res.RateLimits.Remaining != i {
+				t.Errorf("got rate limits of another call: %+v", res.RateLimits)
+			}
+			if limits := c.LastRateLimits(); limits.Limit != 1000 {
+				t.Errorf("unexpected rate limits %+v", limits)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestResetTime(t *testing.T) {
+	if !(RateLimits{}).ResetTime().IsZero() {
+		t.Error("unknown reset time must be zero")
+	}
+}
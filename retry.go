@@ -0,0 +1,201 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// A RetryPolicy defines when and how often a failed API call is retried.
+// Calls are retried on network errors and on responses whose status is
+// one of RetryableStatuses. Between attempts the client waits with exponential
+// backoff and jitter, unless the response tells how long to wait through
+// the Retry-After or X-RateLimit-Reset headers.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+
+	// MinBackoff is the wait after the first failed attempt. It doubles
+	// with every further attempt up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// MaxWait is the longest wait the client accepts from Retry-After or
+	// X-RateLimit-Reset. If the server asks for a longer wait, the call fails
+	// without retrying. Zero means no limit.
+	MaxWait time.Duration
+
+	// RetryableStatuses is the list of HTTP statuses that are retried.
+	// Default is 429, 500, 502, 503 and 504.
+	RetryableStatuses []int
+
+	// OnRetry, if not nil, is called before waiting for each retry with the
+	// endpoint path, the number of the attempt that failed, its error and the wait.
+	OnRetry func(endpoint string, attempt int, err error, wait time.Duration)
+}
+
+// DefaultRetryPolicy returns the retry policy used by WithRetries.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:       4,
+		MinBackoff:        500 * time.Millisecond,
+		MaxBackoff:        30 * time.Second,
+		MaxWait:           2 * time.Minute,
+		RetryableStatuses: []int{429, 500, 502, 503, 504},
+	}
+}
+
+// WithRetries makes the client retry failed calls using DefaultRetryPolicy.
+func WithRetries() Option {
+	return WithRetryPolicy(DefaultRetryPolicy())
+}
+
+// WithRetryPolicy makes the client retry failed calls according to policy.
+// Zero fields of policy are set from DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) error {
+		if policy.MaxAttempts < 0 || policy.MinBackoff < 0 || policy.MaxBackoff < 0 || policy.MaxWait < 0 {
+			return errors.New("retry policy values must not be negative")
+		}
+
+		defaults := DefaultRetryPolicy()
+		if policy.MaxAttempts == 0 {
+			policy.MaxAttempts = defaults.MaxAttempts
+		}
+		if policy.MinBackoff == 0 {
+			policy.MinBackoff = defaults.MinBackoff
+		}
+		if policy.MaxBackoff == 0 {
+			policy.MaxBackoff = defaults.MaxBackoff
+		}
+		if policy.MaxBackoff < policy.MinBackoff {
+			policy.MaxBackoff = policy.MinBackoff
+		}
+		if policy.RetryableStatuses == nil {
+			policy.RetryableStatuses = defaults.RetryableStatuses
+		}
+
+		c.retry = &policy
+		return nil
+	}
+}
+
+// delay returns how long to wait before retrying a call whose attempt failed with err,
+// and whether the call should be retried at all.
+func (p *RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if !p.retryableStatus(apiErr.StatusCode) {
+			return 0, false
+		}
+		if wait, ok := serverDelay(apiErr.StatusCode, apiErr.Header, time.Now()); ok {
+			if p.MaxWait > 0 && wait > p.MaxWait {
+				return 0, false
+			}
+			return wait, true
+		}
+	} else {
+		var urlErr *url.Error
+		if !errors.As(err, &urlErr) {
+			return 0, false
+		}
+	}
+
+	return p.backoff(attempt), true
+}
+
+func (p *RetryPolicy) retryableStatus(status int) bool {
+	for _, s := range p.RetryableStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the exponential backoff after the given attempt,
+// with a random jitter of up to half of it.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	half := int64(d / 2)
+	if half <= 0 {
+		return d
+	}
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// serverDelay returns the wait requested by the server through the
+// Retry-After header, or through X-RateLimit-Reset when the rate limit was hit.
+func serverDelay(status int, header http.Header, now time.Time) (time.Duration, bool) {
+	if v := header.Get("Retry-After"); len(v) > 0 {
+		if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return nonNegative(t.Sub(now)), true
+		}
+	}
+
+	if status == http.StatusTooManyRequests || header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil && reset > 0 {
+			return nonNegative(time.Unix(reset, 0).Sub(now)), true
+		}
+	}
+
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// sleep waits for d or until ctx is done, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newFlakyServer(failures int32, status int) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			w.WriteHeader(status)
+			fmt.Fprintln(w, `{"error": "try again later"}`)
+			return
+		}
+		fmt.Fprintln(w, `{"polarity": "positive"}`)
+	}))
+	return server, &requests
+}
+
+func TestRetry(t *testing.T) {
+	server, requests := newFlakyServer(2, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	var retries []int
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		OnRetry: func(endpoint string, attempt int, err error, wait time.Duration) {
+			if endpoint != "/sentiment" {
+				t.Errorf("unexpected endpoint %q", endpoint)
+			}
+			retries = append(retries, attempt)
+		},
+	}))
+	sentiment, err := c.Sentiment(&SentimentParams{Text: "text"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sentiment.Polarity != "positive" {
+		t.Error("invalid response")
+	}
+	if *requests != 3 || len(retries) != 2 || retries[0] != 1 || retries[1] != 2 {
+		t.Errorf("unexpected retries %v after %d requests", retries, *requests)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	server, requests := newFlakyServer(5, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+	}))
+	_, err := c.Sentiment(&SentimentParams{Text: "text"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "try again later" || !errors.Is(err, ErrServer) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if *requests != 3 {
+		t.Errorf("expected 3 requests, got %d", *requests)
+	}
+}
+
+func TestNoRetry(t *testing.T) {
+	for _, opts := range [][]Option{
+		nil,
+		{WithRetryPolicy(RetryPolicy{MinBackoff: time.Millisecond})},
+	} {
+		server, requests := newFlakyServer(1, http.StatusBadRequest)
+		c, _ := NewClient(auth, false, append(opts, WithBaseURL(server.URL))...)
+		if _, err := c.Sentiment(&SentimentParams{Text: "text"}); err == nil {
+			t.Error("did not return error")
+		}
+		if *requests != 1 {
+			t.Errorf("expected 1 request, got %d", *requests)
+		}
+		server.Close()
+	}
+}
+
+func TestRetryNetworkError(t *testing.T) {
+	server, _ := newFlakyServer(0, 0)
+	server.Close()
+
+	var retries int
+	c, _ := NewClient(auth, false, WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 2,
+		MinBackoff:  time.Millisecond,
+		OnRetry: func(endpoint string, attempt int, err error, wait time.Duration) {
+			retries++
+		},
+	}))
+	if _, err := c.Sentiment(&SentimentParams{Text: "text"}); err == nil {
+		t.Error("did not return error")
+	}
+	if retries != 1 {
+		t.Errorf("expected 1 retry, got %d", retries)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	now := time.Now()
+
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+	if wait, ok := policy.delay(1, &APIError{StatusCode: 503, Header: header}); !ok || wait != 7*time.Second {
+		t.Errorf("Retry-After not honoured: %v %v", wait, ok)
+	}
+
+	header = http.Header{}
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Minute).Unix(), 10))
+	header.Set("X-RateLimit-Remaining", "0")
+	if wait, ok := policy.delay(1, &APIError{StatusCode: 429, Header: header}); !ok || wait < 58*time.Second || wait > time.Minute {
+		t.Errorf("X-RateLimit-Reset not honoured: %v %v", wait, ok)
+	}
+
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Hour).Unix(), 10))
+	if _, ok := policy.delay(1, &APIError{StatusCode: 429, Header: header}); ok {
+		t.Error("must not wait longer than MaxWait")
+	}
+
+	if _, ok := policy.delay(policy.MaxAttempts, &APIError{StatusCode: 503, Header: http.Header{}}); ok {
+		t.Error("must not retry after MaxAttempts")
+	}
+
+	for attempt := 1; attempt < 10; attempt++ {
+		wait := policy.backoff(attempt)
+		if wait < policy.MinBackoff/2 || wait > policy.MaxBackoff {
+			t.Errorf("attempt %d: backoff %v out of bounds", attempt, wait)
+		}
+	}
+}
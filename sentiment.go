@@ -17,20 +17,21 @@ limitations under the License.
 package textapi
 
 import (
-	"errors"
-	"net/url"
+	"context"
 )
 
 // SentimentParams is the set of parameters that defines a document whose sentiment needs analysis.
 type SentimentParams struct {
-	// Either URL or Text is required.
-	Text string
-	URL  string
+	// Either Input, Text or URL is required, see Input.
+	Input Input
+	Text  string
+	URL   string
 
 	// The analyze mode.
 	// Valid option are tweet suitable for short text (default).
 	// And document which is more suitable for longer bodies of text.
-	Mode string
+	// See SentimentMode.
+	Mode SentimentMode `form:"mode,omitempty,enum=sentiment_mode"`
 }
 
 // SentimentResponse is the JSON description of sentiment analysis.
@@ -46,22 +47,19 @@ type SentimentResponse struct {
 // It detects the sentiment in terms of polarity (positive, negative or neutral).
 // And in terms of subjectivity (subjective or objective).
 func (c *Client) Sentiment(params *SentimentParams) (*SentimentResponse, error) {
-	body := &url.Values{}
-
-	if len(params.Text) > 0 {
-		body.Add("text", params.Text)
-	} else if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else {
-		return nil, errors.New("you must either provide url or text")
-	}
+	return c.SentimentContext(context.Background(), params)
+}
 
-	if len(params.Mode) > 0 {
-		body.Add("mode", params.Mode)
+// SentimentContext is like Sentiment but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) SentimentContext(ctx context.Context, params *SentimentParams, opts ...CallOption) (*SentimentResponse, error) {
+	body, err := c.encode(EndpointSentiment, inputOf(params.Input, params.Text, "", params.URL), params)
+	if err != nil {
+		return nil, err
 	}
 
 	sentiment := &SentimentResponse{}
-	err := c.call("/sentiment", body, sentiment)
+	err = c.call(ctx, "/sentiment", body, sentiment, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -17,14 +17,16 @@ limitations under the License.
 package textapi
 
 import (
+	"context"
 	"errors"
-	"net/url"
-	"strconv"
 )
 
 // SummarizeParams is the set of parameters that defines a document whose needs to be summarized.
 type SummarizeParams struct {
-	// Either URL or a pair of Title and Text is required.
+	// Either Input, URL or Text is required, see Input.
+	// Title is required with a text input. Text takes precedence over URL
+	// only along with a Title: a Text without Title falls back to URL if it is set.
+	Input Input
 	URL   string
 	Text  string
 	Title string
@@ -32,12 +34,13 @@ type SummarizeParams struct {
 	// Summarize mode
 	// Valid options are default, and short.
 	// short mode produces relatively shorter sentences.
-	Mode string
+	// See SummarizeMode.
+	Mode SummarizeMode `form:"mode,default=default,enum=summarize_mode"`
 
 	// Quantity of sentences in default mode.
 	// Not applicable to short mode.
-	NumberOfSentences     int
-	PercentageOfSentences int
+	NumberOfSentences     int `form:"sentences_number,omitempty,min=1"`
+	PercentageOfSentences int `form:"sentences_percentage,omitempty,min=1,max=100"`
 }
 
 // SummarizeResponse is the JSON description of summarize response.
@@ -48,33 +51,32 @@ type SummarizeResponse struct {
 
 // Summarize summarizes the document defined by the given params information.
 func (c *Client) Summarize(params *SummarizeParams) (*SummarizeResponse, error) {
-	body := &url.Values{}
+	return c.SummarizeContext(context.Background(), params)
+}
 
-	if len(params.URL) > 0 {
-		body.Add("url", params.URL)
-	} else if len(params.Title) > 0 && len(params.Text) > 0 {
-		body.Add("title", params.Title)
-		body.Add("text", params.Text)
-	} else {
-		return nil, errors.New("you must either provide url or a pair of text and title")
+// SummarizeContext is like Summarize but uses ctx to carry cancellation and deadline
+// through the underlying API call.
+func (c *Client) SummarizeContext(ctx context.Context, params *SummarizeParams, opts ...CallOption) (*SummarizeResponse, error) {
+	var errs []error
+	input := inputOf(params.Input, params.Text, "", params.URL)
+	if params.Input.IsZero() && len(params.Title) == 0 && len(params.URL) > 0 {
+		input = URLInput(params.URL)
 	}
-
-	if len(params.Mode) > 0 {
-		body.Add("mode", params.Mode)
-	} else {
-		body.Add("mode", "default")
+	if input.kind == inputText && len(params.Title) == 0 {
+		errs = append(errs, errors.New("you must provide a title with a text"))
 	}
 
-	if params.NumberOfSentences > 0 {
-		body.Add("sentences_number", strconv.Itoa(params.NumberOfSentences))
+	body, err := c.encode(EndpointSummarize, input, params, errs...)
+	if err != nil {
+		return nil, err
 	}
-
-	if params.PercentageOfSentences > 0 {
-		body.Add("sentences_percentage", strconv.Itoa(params.PercentageOfSentences))
+	// The title only goes with a text.
+	if input.kind == inputText {
+		body.Set("title", params.Title)
 	}
 
 	summary := &SummarizeResponse{}
-	err := c.call("/summarize", body, summary)
+	err = c.call(ctx, "/summarize", body, summary, opts...)
 	if err != nil {
 		return nil, err
 	}
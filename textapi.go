@@ -18,18 +18,22 @@ package textapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
-	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // version is SDK's version.
-const version = "0.4.0"
+const version = "0.5.0"
 
 // An Auth is an authentication token that will be used to
 // authenticate client.
@@ -40,22 +44,122 @@ type Auth struct {
 	ApplicationKey string
 }
 
+// String returns a with its application key redacted.
+func (a Auth) String() string {
+	return "{" + a.ApplicationID + " " + redactedKey + "}"
+}
+
+// Format makes every fmt verb print a with its application key redacted.
+func (a Auth) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprintf(f, "textapi.Auth{ApplicationID:%q, ApplicationKey:%q}", a.ApplicationID, redactedKey)
+	case verb == 'v' && f.Flag('+'):
+		fmt.Fprintf(f, "{ApplicationID:%s ApplicationKey:%s}", a.ApplicationID, redactedKey)
+	default:
+		io.WriteString(f, a.String())
+	}
+}
+
+// LogValue makes log/slog log a with its application key redacted.
+func (a Auth) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("application_id", a.ApplicationID),
+		slog.String("application_key", redactedKey),
+	)
+}
+
 // A RateLimits is the HTTP X-RateLimit-* headers of last response.
 type RateLimits struct {
 	Limit     int
 	Remaining int
-	Reset     int
+	Reset     int // Unix time in seconds, see ResetTime
 }
 
+// ResetTime returns the time at which the rate limit is reset.
+// It returns the zero time if the reset time is unknown.
+func (r RateLimits) ResetTime() time.Time {
+	if r.Reset <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(r.Reset), 0)
+}
+
+// parseRateLimits returns the rate limits described by the X-RateLimit-* headers of header.
+func parseRateLimits(header http.Header) RateLimits {
+	var r RateLimits
+	r.Limit, _ = strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	r.Reset, _ = strconv.Atoi(header.Get("X-RateLimit-Reset"))
+	r.Remaining, _ = strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	return r
+}
+
+// defaultHostAndPath is the host and path prefix of the public Text API.
+const defaultHostAndPath = "api.aylien.com/api/v1"
+
 // A Client can make calls to the Text API.
 type Client struct {
-	auth           Auth
-	useHTTPS       bool
-	apiHostAndPath string
+	credentials CredentialsProvider
+	useHTTPS    bool
+	baseURL     string
+
+	httpClient *http.Client
+	transport  http.RoundTripper
+	timeout    time.Duration
+	userAgent  string
 
+	maxResponseSize int64
+	defaultLanguage Language
+	retry           *RetryPolicy
+	limiter         *rateLimiter
+
+	interceptors []Interceptor
+	invoker      Invoker
+
+	logger  *logger
+	metrics Metrics
+	tracer  Tracer
+
+	cache      Cache
+	cacheTTL   time.Duration
+	cacheTTLs  map[string]time.Duration
+	cacheStats cacheStats
+
+	staleIfError bool
+	maxStale     time.Duration
+
+	flights *flightGroup
+
+	// keepBodies is whether the raw bodies of successful responses are kept in Call.
+	keepBodies bool
+
+	mu         sync.Mutex
+	rateLimits RateLimits
+
+	// RateLimits is updated after every call.
+	//
+	// Deprecated: reading RateLimits while calls are in flight is racy.
+	// Use LastRateLimits, or CaptureResponse for the rate limits of a given call.
 	RateLimits *RateLimits
 }
 
+// String describes c with the base URL it calls and its credentials,
+// without their application keys. Credentials that are not a fmt.Stringer
+// are only described by their type.
+func (c *Client) String() string {
+	credentials := fmt.Sprintf("%T", c.credentials)
+	if s, ok := c.credentials.(fmt.Stringer); ok {
+		credentials = s.String()
+	}
+	return "textapi.Client{" + c.baseURL + " " + credentials + "}"
+}
+
+// Format makes every fmt verb print c as String does,
+// since printing its fields would expose its application keys.
+func (c *Client) Format(f fmt.State, verb rune) {
+	io.WriteString(f, c.String())
+}
+
 // An Error is the JSON response whenever an error occurs.
 type Error struct {
 	Message string `json:"error"`
@@ -63,52 +167,233 @@ type Error struct {
 
 // NewClient returns a new client using the given auth information.
 // To use HTTPS, pas useHttps = true.
-func NewClient(auth Auth, useHTTPS bool) (*Client, error) {
-	if len(auth.ApplicationID) == 0 || len(auth.ApplicationKey) == 0 {
-		return nil, errors.New("invalid application ID or application key")
-	}
+// Additional options can be passed to customize the client, see Option.
+// When the WithCredentials option is used, auth may be left empty.
+func NewClient(auth Auth, useHTTPS bool, opts ...Option) (*Client, error) {
 	client := &Client{
-		auth:           auth,
-		useHTTPS:       useHTTPS,
-		apiHostAndPath: "api.aylien.com/api/v1",
-		RateLimits:     &RateLimits{},
+		useHTTPS:   useHTTPS,
+		userAgent:  "Aylien Text API Go " + version,
+		RateLimits: &RateLimits{},
+
+		maxResponseSize: defaultMaxResponseSize,
+		tracer:          noopTracer{},
+	}
+
+	for _, opt := range opts {
+		if err := opt(client); err != nil {
+			return nil, err
+		}
+	}
+
+	if client.credentials == nil {
+		if len(auth.ApplicationID) == 0 || len(auth.ApplicationKey) == 0 {
+			return nil, errors.New("invalid application ID or application key")
+		}
+		client.credentials = staticCredentials{auth}
+	}
+
+	if len(client.baseURL) == 0 {
+		protocol := "http"
+		if useHTTPS {
+			protocol = "https"
+		}
+		client.baseURL = protocol + "://" + defaultHostAndPath
+	}
+
+	if client.httpClient == nil {
+		client.httpClient = &http.Client{Transport: newTransport()}
+	}
+	if client.transport != nil {
+		httpClient := *client.httpClient
+		httpClient.Transport = client.transport
+		client.httpClient = &httpClient
 	}
 
+	if client.staleIfError {
+		if _, ok := client.cache.(StaleCache); !ok {
+			return nil, errors.New("WithStaleIfError requires a cache implementing StaleCache")
+		}
+	}
+
+	invoker := client.send
+	if client.flights != nil {
+		invoker = client.deduplicated(invoker)
+	}
+	if client.cache != nil {
+		invoker = client.cached(invoker)
+	}
+	client.invoker = chainInterceptors(client.interceptors, invoker)
+
 	return client, nil
 }
 
-func (c *Client) call(path string, form *url.Values, v interface{}) error {
-	req, err := c.newRequest(path, form)
-	if err != nil {
-		return err
+// language returns the language parameter to send, given the one of the call params.
+func (c *Client) language(language Language) Language {
+	if len(language) > 0 {
+		return language
 	}
+	return c.defaultLanguage
+}
 
-	if err := c.do(req, v); err != nil {
-		return err
-	}
+// LastRateLimits returns the rate limits sent with the last response received by the client.
+// It is safe to call while other calls are in flight.
+func (c *Client) LastRateLimits() RateLimits {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rateLimits
+}
 
-	return nil
+func (c *Client) setRateLimits(r RateLimits) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimits = r
+	*c.RateLimits = r
+	if _, ok := c.credentials.(staticCredentials); ok && c.limiter != nil {
+		c.limiter.update(r)
+	}
 }
 
-func (c *Client) newRequest(path string, form *url.Values) (*http.Request, error) {
+func (c *Client) call(ctx context.Context, path string, form *url.Values, v interface{}, opts ...CallOption) error {
 	if !strings.HasPrefix(path, "/") {
 		path = "/" + path
 	}
 
-	protocol := "http"
-	if c.useHTTPS {
-		protocol = "https"
+	var o callOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	call := &Call{
+		Endpoint: path,
+		Form:     url.Values{},
+		Header:   http.Header{},
+		Result:   v,
+		Response: &Response{},
+		options:  o,
+	}
+	if form != nil {
+		call.Form = *form
+	}
+	if o.response != nil {
+		defer func() {
+			*o.response = *call.Response
+		}()
 	}
 
-	url := protocol + "://" + c.apiHostAndPath + path
+	return c.invoker(ctx, call)
+}
+
+// send is the Invoker at the end of the interceptor chain.
+// It sends call to the API, retrying it as allowed by the retry policy.
+func (c *Client) send(ctx context.Context, call *Call) (err error) {
+	ctx, span := c.tracer.Start(ctx, call.Endpoint)
+	span.SetAttributes(callAttributes(call)...)
+
+	meta := call.Response
+	start := time.Now()
+	defer func() {
+		meta.Latency = time.Since(start)
+
+		retries := meta.Attempts - 1
+		if retries < 0 {
+			retries = 0
+		}
+		span.SetAttributes(
+			Attribute{AttributeStatusCode, meta.StatusCode},
+			Attribute{AttributeRetryCount, retries},
+		)
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+
+		if c.logger != nil {
+			c.logger.logCall(ctx, call, err)
+		}
+		if c.metrics != nil {
+			c.metrics.ObserveCall(call.Endpoint, meta.StatusCode, meta.Latency, err)
+		}
+	}()
+
+	// rotations counts the attempts retried right away with other credentials,
+	// which do not count against the retry policy.
+	rotations := 0
+	for attempt := 1; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		auth, err := c.credentials.Credentials(ctx)
+		if err != nil {
+			return err
+		}
+
+		req, err := c.newRequest(ctx, call, auth)
+		if err != nil {
+			return err
+		}
+
+		*meta = Response{
+			Attempts:      attempt,
+			RequestHeader: redactHeader(req.Header),
+		}
+		err = c.do(call, req)
+		rotate := c.credentials.Report(auth, meta.RateLimits, err)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if rotate {
+			rotations++
+			c.observeRetry(ctx, call, attempt, err, 0)
+			continue
+		}
+
+		wait, retry := c.retry.delay(attempt-rotations, err)
+		if !retry {
+			return err
+		}
+		c.observeRetry(ctx, call, attempt, err, wait)
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// observeRetry reports a failed attempt of call that is about to be retried after wait.
+func (c *Client) observeRetry(ctx context.Context, call *Call, attempt int, err error, wait time.Duration) {
+	if c.logger != nil {
+		c.logger.logRetry(ctx, call, attempt, err, wait)
+	}
+	if c.metrics != nil {
+		c.metrics.ObserveRetry(call.Endpoint, call.Response.StatusCode)
+	}
+	if c.retry != nil && c.retry.OnRetry != nil {
+		c.retry.OnRetry(call.Endpoint, attempt, err, wait)
+	}
+}
+
+func (c *Client) newRequest(ctx context.Context, call *Call, auth Auth) (*http.Request, error) {
+	url := c.baseURL + call.Endpoint
 
 	var body io.Reader
-	if form != nil && len(*form) > 0 {
-		data := form.Encode()
+	if len(call.Form) > 0 {
+		data := call.Form.Encode()
 		body = bytes.NewBufferString(data)
 	}
 
-	req, err := http.NewRequest("POST", url, body)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -116,44 +401,79 @@ func (c *Client) newRequest(path string, form *url.Values) (*http.Request, error
 	if body != nil {
 		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	}
-	req.Header.Add("User-Agent", "Aylien Text API Go "+version)
-	req.Header.Add("X-AYLIEN-TextAPI-Application-ID", c.auth.ApplicationID)
-	req.Header.Add("X-AYLIEN-TextAPI-Application-Key", c.auth.ApplicationKey)
+	req.Header.Add("User-Agent", c.userAgent)
+	for k, v := range call.Header {
+		req.Header[k] = v
+	}
+	c.tracer.Inject(ctx, req.Header)
+	req.Header.Set("X-AYLIEN-TextAPI-Application-ID", auth.ApplicationID)
+	req.Header.Set("X-AYLIEN-TextAPI-Application-Key", auth.ApplicationKey)
 
 	return req, nil
 }
 
-func (c *Client) do(req *http.Request, v interface{}) error {
-	client := &http.Client{}
-	res, err := client.Do(req)
+func (c *Client) do(call *Call, req *http.Request) error {
+	meta := call.Response
+	res, err := c.httpClient.Do(req)
 
 	if err != nil {
 		return err
 	}
-	defer res.Body.Close()
+	defer closeBody(res.Body)
 
-	resBody, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return err
+	meta.StatusCode = res.StatusCode
+	meta.Header = res.Header
+	meta.RateLimits = parseRateLimits(res.Header)
+	if res.StatusCode < 300 || len(res.Header.Get("X-RateLimit-Limit")) > 0 {
+		c.setRateLimits(meta.RateLimits)
+	}
+	if c.metrics != nil && meta.RateLimits.Limit > 0 {
+		c.metrics.ObserveRateLimits(meta.RateLimits)
+	}
+
+	if res.ContentLength > c.maxResponseSize {
+		return ErrResponseTooLarge
 	}
+	body := &limitedReader{r: res.Body, n: c.maxResponseSize}
 
 	if res.StatusCode >= 300 {
+		resBody, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		call.body = resBody
+
+		message := string(resBody)
 		var e Error
-		if err = json.Unmarshal(resBody, &e); err != nil {
-			return errors.New(string(resBody))
+		if err = json.Unmarshal(resBody, &e); err == nil {
+			message = e.Message
 		}
 
-		return errors.New(e.Message)
+		return &APIError{
+			StatusCode: res.StatusCode,
+			Endpoint:   call.Endpoint,
+			Message:    message,
+			Body:       resBody,
+			Header:     res.Header,
+		}
 	}
 
-	c.RateLimits.Limit, _ = strconv.Atoi(res.Header.Get("X-RateLimit-Limit"))
-	c.RateLimits.Reset, _ = strconv.Atoi(res.Header.Get("X-RateLimit-Reset"))
-	c.RateLimits.Remaining, _ = strconv.Atoi(res.Header.Get("X-RateLimit-Remaining"))
+	if call.Result != nil {
+		var r io.Reader = body
+		if c.keepBodies {
+			buf := &bytes.Buffer{}
+			r = io.TeeReader(body, buf)
+			defer func() {
+				call.body = buf.Bytes()
+			}()
+		}
 
-	if v != nil {
-		err = json.Unmarshal(resBody, v)
+		err = json.NewDecoder(r).Decode(call.Result)
+		if errors.Is(err, ErrResponseTooLarge) {
+			return err
+		}
 		if err != nil {
-			return errors.New("invalid response")
+			return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
 		}
 
 		return nil
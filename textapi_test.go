@@ -17,12 +17,14 @@ limitations under the License.
 package textapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -34,7 +36,6 @@ var (
 
 func init() {
 	auth = Auth{"test", "test"}
-	client, _ = NewClient(auth, false)
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get("X-Aylien-Textapi-Application-Key") != "test" ||
 			r.Header.Get("X-Aylien-Textapi-Application-Id") != "test" {
@@ -86,12 +87,14 @@ func init() {
 				bytes, _ = json.Marshal(MicroformatsResponse{})
 			case "/image-tags":
 				bytes, _ = json.Marshal(ImageTagsResponse{})
+			case "/combined":
+				bytes, _ = json.Marshal(combinedRawResponse{})
 			}
 			fmt.Fprintln(w, string(bytes))
 		}
 	}))
-	testServerURL = strings.TrimLeft(testServer.URL, "http://")
-	client.apiHostAndPath = testServerURL
+	testServerURL = testServer.URL
+	client, _ = NewClient(auth, false, WithBaseURL(testServerURL))
 }
 
 func TestClientCreation(t *testing.T) {
@@ -104,14 +107,32 @@ func TestClientCreation(t *testing.T) {
 
 func TestInvalidKeys(t *testing.T) {
 	badAuth := Auth{"wrongtest", "wrongtest"}
-	badClient, _ := NewClient(badAuth, false)
-	badClient.apiHostAndPath = testServerURL
+	badClient, _ := NewClient(badAuth, false, WithBaseURL(testServerURL))
 	params := &LanguageParams{Text: "Hello"}
 	if _, err := badClient.Language(params); err == nil {
 		t.Error("did not return error")
 	}
 }
 
+func TestContextCancellation(t *testing.T) {
+	done := make(chan struct{})
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer slowServer.Close()
+	defer close(done)
+
+	slowClient, _ := NewClient(auth, false, WithBaseURL(slowServer.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	params := &SentimentParams{Text: "John is a very good football player!"}
+	_, err := slowClient.SentimentContext(ctx, params)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
 func TestSentiment(t *testing.T) {
 	params := &SentimentParams{}
 	_, err := client.Sentiment(params)
@@ -0,0 +1,240 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package textapitest provides fakes of the Text API for testing code that uses it.
+package textapitest
+
+import (
+	"context"
+	"sync"
+
+	textapi "github.com/AYLIEN/aylien_textapi_go"
+)
+
+// A Call is a call recorded by an Analyzer.
+type Call struct {
+	// Endpoint is the endpoint the call would have been sent to, e.g. sentiment.
+	Endpoint textapi.Endpoint
+
+	// Params are the params of the call, e.g. a *textapi.SentimentParams.
+	Params interface{}
+}
+
+// An Analyzer is an in-memory textapi.TextAnalyzer, for testing code
+// that depends on a TextAnalyzer instead of a *textapi.Client.
+//
+// Each call is recorded, then answered by the function field of its method if it is set,
+// and otherwise with an empty response. Calls with a done context fail with its error.
+// The zero Analyzer is ready to use, and is safe for concurrent use
+// as long as its function fields are not modified during calls.
+type Analyzer struct {
+	SentimentFunc            func(ctx context.Context, params *textapi.SentimentParams) (*textapi.SentimentResponse, error)
+	EntitiesFunc             func(ctx context.Context, params *textapi.EntitiesParams) (*textapi.EntitiesResponse, error)
+	ConceptsFunc             func(ctx context.Context, params *textapi.ConceptsParams) (*textapi.ConceptsResponse, error)
+	ClassifyFunc             func(ctx context.Context, params *textapi.ClassifyParams) (*textapi.ClassifyResponse, error)
+	ClassifyByTaxonomyFunc   func(ctx context.Context, params *textapi.ClassifyByTaxonomyParams) (*textapi.ClassifyByTaxonomyResponse, error)
+	UnsupervisedClassifyFunc func(ctx context.Context, params *textapi.UnsupervisedClassifyParams) (*textapi.UnsupervisedClassifyResponse, error)
+	HashtagsFunc             func(ctx context.Context, params *textapi.HashtagsParams) (*textapi.HashtagsResponse, error)
+	LanguageFunc             func(ctx context.Context, params *textapi.LanguageParams) (*textapi.LanguageResponse, error)
+	SummarizeFunc            func(ctx context.Context, params *textapi.SummarizeParams) (*textapi.SummarizeResponse, error)
+	ExtractFunc              func(ctx context.Context, params *textapi.ExtractParams) (*textapi.ExtractResponse, error)
+	RelatedFunc              func(ctx context.Context, params *textapi.RelatedParams) (*textapi.RelatedResponse, error)
+	ImageTagsFunc            func(ctx context.Context, params *textapi.ImageTagsParams) (*textapi.ImageTagsResponse, error)
+	MicroformatsFunc         func(ctx context.Context, params *textapi.MicroformatsParams) (*textapi.MicroformatsResponse, error)
+	CombinedFunc             func(ctx context.Context, params *textapi.CombinedParams) (*textapi.CombinedResponse, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ textapi.TextAnalyzer = (*Analyzer)(nil)
+
+// Calls returns the calls received by a, in order.
+func (a *Analyzer) Calls() []Call {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]Call(nil), a.calls...)
+}
+
+// Reset forgets the calls received by a.
+func (a *Analyzer) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls = nil
+}
+
+// record records a call to endpoint and returns the error of ctx, if it is done.
+func (a *Analyzer) record(ctx context.Context, endpoint textapi.Endpoint, params interface{}) error {
+	a.mu.Lock()
+	a.calls = append(a.calls, Call{Endpoint: endpoint, Params: params})
+	a.mu.Unlock()
+	return ctx.Err()
+}
+
+// SentimentContext calls a.SentimentFunc, see Analyzer.
+func (a *Analyzer) SentimentContext(ctx context.Context, params *textapi.SentimentParams, opts ...textapi.CallOption) (*textapi.SentimentResponse, error) {
+	if err := a.record(ctx, textapi.EndpointSentiment, params); err != nil {
+		return nil, err
+	}
+	if a.SentimentFunc != nil {
+		return a.SentimentFunc(ctx, params)
+	}
+	return &textapi.SentimentResponse{}, nil
+}
+
+// EntitiesContext calls a.EntitiesFunc, see Analyzer.
+func (a *Analyzer) EntitiesContext(ctx context.Context, params *textapi.EntitiesParams, opts ...textapi.CallOption) (*textapi.EntitiesResponse, error) {
+	if err := a.record(ctx, textapi.EndpointEntities, params); err != nil {
+		return nil, err
+	}
+	if a.EntitiesFunc != nil {
+		return a.EntitiesFunc(ctx, params)
+	}
+	return &textapi.EntitiesResponse{}, nil
+}
+
+// ConceptsContext calls a.ConceptsFunc, see Analyzer.
+func (a *Analyzer) ConceptsContext(ctx context.Context, params *textapi.ConceptsParams, opts ...textapi.CallOption) (*textapi.ConceptsResponse, error) {
+	if err := a.record(ctx, textapi.EndpointConcepts, params); err != nil {
+		return nil, err
+	}
+	if a.ConceptsFunc != nil {
+		return a.ConceptsFunc(ctx, params)
+	}
+	return &textapi.ConceptsResponse{}, nil
+}
+
+// ClassifyContext calls a.ClassifyFunc, see Analyzer.
+func (a *Analyzer) ClassifyContext(ctx context.Context, params *textapi.ClassifyParams, opts ...textapi.CallOption) (*textapi.ClassifyResponse, error) {
+	if err := a.record(ctx, textapi.EndpointClassify, params); err != nil {
+		return nil, err
+	}
+	if a.ClassifyFunc != nil {
+		return a.ClassifyFunc(ctx, params)
+	}
+	return &textapi.ClassifyResponse{}, nil
+}
+
+// ClassifyByTaxonomyContext calls a.ClassifyByTaxonomyFunc, see Analyzer.
+func (a *Analyzer) ClassifyByTaxonomyContext(ctx context.Context, params *textapi.ClassifyByTaxonomyParams, opts ...textapi.CallOption) (*textapi.ClassifyByTaxonomyResponse, error) {
+	if err := a.record(ctx, textapi.Endpoint("classify/"+string(params.Taxonomy)), params); err != nil {
+		return nil, err
+	}
+	if a.ClassifyByTaxonomyFunc != nil {
+		return a.ClassifyByTaxonomyFunc(ctx, params)
+	}
+	return &textapi.ClassifyByTaxonomyResponse{}, nil
+}
+
+// UnsupervisedClassifyContext calls a.UnsupervisedClassifyFunc, see Analyzer.
+func (a *Analyzer) UnsupervisedClassifyContext(ctx context.Context, params *textapi.UnsupervisedClassifyParams, opts ...textapi.CallOption) (*textapi.UnsupervisedClassifyResponse, error) {
+	if err := a.record(ctx, textapi.EndpointUnsupervisedClassify, params); err != nil {
+		return nil, err
+	}
+	if a.UnsupervisedClassifyFunc != nil {
+		return a.UnsupervisedClassifyFunc(ctx, params)
+	}
+	return &textapi.UnsupervisedClassifyResponse{}, nil
+}
+
+// HashtagsContext calls a.HashtagsFunc, see Analyzer.
+func (a *Analyzer) HashtagsContext(ctx context.Context, params *textapi.HashtagsParams, opts ...textapi.CallOption) (*textapi.HashtagsResponse, error) {
+	if err := a.record(ctx, textapi.EndpointHashtags, params); err != nil {
+		return nil, err
+	}
+	if a.HashtagsFunc != nil {
+		return a.HashtagsFunc(ctx, params)
+	}
+	return &textapi.HashtagsResponse{}, nil
+}
+
+// LanguageContext calls a.LanguageFunc, see Analyzer.
+func (a *Analyzer) LanguageContext(ctx context.Context, params *textapi.LanguageParams, opts ...textapi.CallOption) (*textapi.LanguageResponse, error) {
+	if err := a.record(ctx, textapi.EndpointLanguage, params); err != nil {
+		return nil, err
+	}
+	if a.LanguageFunc != nil {
+		return a.LanguageFunc(ctx, params)
+	}
+	return &textapi.LanguageResponse{}, nil
+}
+
+// SummarizeContext calls a.SummarizeFunc, see Analyzer.
+func (a *Analyzer) SummarizeContext(ctx context.Context, params *textapi.SummarizeParams, opts ...textapi.CallOption) (*textapi.SummarizeResponse, error) {
+	if err := a.record(ctx, textapi.EndpointSummarize, params); err != nil {
+		return nil, err
+	}
+	if a.SummarizeFunc != nil {
+		return a.SummarizeFunc(ctx, params)
+	}
+	return &textapi.SummarizeResponse{}, nil
+}
+
+// ExtractContext calls a.ExtractFunc, see Analyzer.
+func (a *Analyzer) ExtractContext(ctx context.Context, params *textapi.ExtractParams, opts ...textapi.CallOption) (*textapi.ExtractResponse, error) {
+	if err := a.record(ctx, textapi.EndpointExtract, params); err != nil {
+		return nil, err
+	}
+	if a.ExtractFunc != nil {
+		return a.ExtractFunc(ctx, params)
+	}
+	return &textapi.ExtractResponse{}, nil
+}
+
+// RelatedContext calls a.RelatedFunc, see Analyzer.
+func (a *Analyzer) RelatedContext(ctx context.Context, params *textapi.RelatedParams, opts ...textapi.CallOption) (*textapi.RelatedResponse, error) {
+	if err := a.record(ctx, textapi.EndpointRelated, params); err != nil {
+		return nil, err
+	}
+	if a.RelatedFunc != nil {
+		return a.RelatedFunc(ctx, params)
+	}
+	return &textapi.RelatedResponse{}, nil
+}
+
+// ImageTagsContext calls a.ImageTagsFunc, see Analyzer.
+func (a *Analyzer) ImageTagsContext(ctx context.Context, params *textapi.ImageTagsParams, opts ...textapi.CallOption) (*textapi.ImageTagsResponse, error) {
+	if err := a.record(ctx, textapi.EndpointImageTags, params); err != nil {
+		return nil, err
+	}
+	if a.ImageTagsFunc != nil {
+		return a.ImageTagsFunc(ctx, params)
+	}
+	return &textapi.ImageTagsResponse{}, nil
+}
+
+// MicroformatsContext calls a.MicroformatsFunc, see Analyzer.
+func (a *Analyzer) MicroformatsContext(ctx context.Context, params *textapi.MicroformatsParams, opts ...textapi.CallOption) (*textapi.MicroformatsResponse, error) {
+	if err := a.record(ctx, textapi.EndpointMicroformats, params); err != nil {
+		return nil, err
+	}
+	if a.MicroformatsFunc != nil {
+		return a.MicroformatsFunc(ctx, params)
+	}
+	return &textapi.MicroformatsResponse{}, nil
+}
+
+// CombinedContext calls a.CombinedFunc, see Analyzer.
+// The empty response of CombinedContext returns a result for each of the endpoints of params.
+func (a *Analyzer) CombinedContext(ctx context.Context, params *textapi.CombinedParams, opts ...textapi.CallOption) (*textapi.CombinedResponse, error) {
+	if err := a.record(ctx, textapi.Endpoint("combined"), params); err != nil {
+		return nil, err
+	}
+	if a.CombinedFunc != nil {
+		return a.CombinedFunc(ctx, params)
+	}
+	return &textapi.CombinedResponse{Endpoints: params.Endpoints}, nil
+}
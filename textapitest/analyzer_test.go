@@ -0,0 +1,96 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapitest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	textapi "github.com/AYLIEN/aylien_textapi_go"
+)
+
+// polarity is code under test, which only depends on a SentimentAnalyzer.
+func polarity(a textapi.SentimentAnalyzer, text string) (string, error) {
+	r, err := a.SentimentContext(context.Background(), &textapi.SentimentParams{Text: text})
+	if err != nil {
+		return "", err
+	}
+	return r.Polarity, nil
+}
+
+func TestAnalyzer(t *testing.T) {
+	a := &Analyzer{
+		SentimentFunc: func(ctx context.Context, params *textapi.SentimentParams) (*textapi.SentimentResponse, error) {
+			if params.Text == "fail" {
+				return nil, textapi.ErrServer
+			}
+			return &textapi.SentimentResponse{Text: params.Text, Polarity: "positive"}, nil
+		},
+	}
+
+	if p, err := polarity(a, "great"); err != nil || p != "positive" {
+		t.Errorf("unexpected polarity %q and error %v", p, err)
+	}
+	if _, err := polarity(a, "fail"); !errors.Is(err, textapi.ErrServer) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	r, err := a.EntitiesContext(context.Background(), &textapi.EntitiesParams{Text: "text"})
+	if err != nil || r == nil {
+		t.Errorf("unexpected response %v and error %v", r, err)
+	}
+
+	calls := a.Calls()
+	if len(calls) != 3 || calls[0].Endpoint != textapi.EndpointSentiment || calls[2].Endpoint != textapi.EndpointEntities {
+		t.Errorf("unexpected calls %+v", calls)
+	}
+	if params := calls[0].Params.(*textapi.SentimentParams); params.Text != "great" {
+		t.Errorf("unexpected params %+v", params)
+	}
+
+	a.Reset()
+	if len(a.Calls()) != 0 {
+		t.Error("calls not reset")
+	}
+}
+
+func TestAnalyzerDefaults(t *testing.T) {
+	var a Analyzer
+	ctx := context.Background()
+
+	r, err := a.CombinedContext(ctx, &textapi.CombinedParams{
+		Endpoints: []textapi.Endpoint{textapi.EndpointSentiment, textapi.EndpointHashtags},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.EndpointErr(textapi.EndpointHashtags); err != nil {
+		t.Error(err)
+	}
+
+	a.ClassifyByTaxonomyContext(ctx, &textapi.ClassifyByTaxonomyParams{Taxonomy: textapi.TaxonomyIABQAG})
+	if calls := a.Calls(); calls[1].Endpoint != textapi.EndpointClassifyIABQAG {
+		t.Errorf("unexpected endpoint %s", calls[1].Endpoint)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := a.RelatedContext(cancelled, &textapi.RelatedParams{Phrase: "phrase"}); err != context.Canceled {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// A Mode is the way a Recorder handles requests.
+type Mode int
+
+const (
+	// ModeReplay answers every request with a recorded response,
+	// and fails the requests that were not recorded with ErrNotRecorded.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends every request and records its response,
+	// replacing the recordings of the same request made by earlier Recorders.
+	ModeRecord
+
+	// ModePassthrough sends every request, without recording or replaying it.
+	ModePassthrough
+)
+
+// ErrNotRecorded is returned by a Recorder in ModeReplay for a request that was not recorded.
+var ErrNotRecorded = errors.New("textapitest: request not recorded")
+
+// redactedKey replaces the application key in cassettes.
+const redactedKey = "REDACTED"
+
+// An interaction is a request and its response, as stored in a cassette.
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Form   string      `json:"form"`
+	Header http.Header `json:"header"`
+}
+
+type recordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+// A cassette is the content of a cassette file.
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+// A Recorder is an http.RoundTripper that records the requests sent through it
+// and their responses to a cassette file, and replays them, so that tests
+// calling the Text API can run offline and deterministically:
+//
+//	recorder, err := textapitest.NewRecorder("testdata/sentiment.json", textapitest.ModeReplay, nil)
+//	client, err := textapi.NewClient(auth, true, textapi.WithTransport(recorder))
+//
+// Requests are matched on their method, path and form, whatever the order of
+// its fields. A request recorded more than once is answered with its responses
+// in the order they were recorded, then with the last one.
+// The application key is redacted in cassettes.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette cassette
+
+	// used are the indexes of the interactions replayed or recorded by r.
+	used map[int]bool
+}
+
+// NewRecorder returns a Recorder using the cassette file at path in mode,
+// sending requests through transport, or http.DefaultTransport if it is nil.
+// The cassette must exist in ModeReplay. In ModeRecord, it is created if needed,
+// and written after each recorded response.
+func NewRecorder(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	r := &Recorder{path: path, mode: mode, transport: transport, used: map[int]bool{}}
+	if mode == ModePassthrough {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && mode == ModeRecord:
+		return r, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("textapitest: invalid cassette %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModePassthrough {
+		return r.transport.RoundTrip(req)
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := recordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Form:   normalizeForm(body),
+		Header: redactHeader(req.Header),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+	return r.record(req, body, recorded)
+}
+
+// replay returns the recorded response to req.
+func (r *Recorder) replay(req *http.Request, recorded recordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, in := range r.cassette.Interactions {
+		if !in.Request.matches(recorded) {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("%w: %s %s %s", ErrNotRecorded, recorded.Method, recorded.Path, recorded.Form)
+	}
+	r.used[match] = true
+
+	res := r.cassette.Interactions[match].Response
+	return &http.Response{
+		Status:        strconv.Itoa(res.Status) + " " + http.StatusText(res.Status),
+		StatusCode:    res.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        res.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewBufferString(res.Body)),
+		ContentLength: int64(len(res.Body)),
+		Request:       req,
+	}, nil
+}
+
+// record sends req, whose body was read as body, and records its response.
+func (r *Recorder) record(req *http.Request, body []byte, recorded recordedRequest) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	res, err := r.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(data))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// The recordings of the request made before r are replaced,
+	// those made by r are kept, e.g. for a call that is retried.
+	var interactions []interaction
+	used := map[int]bool{}
+	for i, in := range r.cassette.Interactions {
+		if in.Request.matches(recorded) && !r.used[i] {
+			continue
+		}
+		if r.used[i] {
+			used[len(interactions)] = true
+		}
+		interactions = append(interactions, in)
+	}
+	used[len(interactions)] = true
+	r.cassette.Interactions = append(interactions, interaction{
+		Request:  recorded,
+		Response: recordedResponse{Status: res.StatusCode, Header: res.Header.Clone(), Body: string(data)},
+	})
+	r.used = used
+
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// save writes the cassette of r to its file atomically. r.mu must be held.
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "cassette-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// matches returns whether a request recorded as other is the same as the request recorded as r.
+func (r recordedRequest) matches(other recordedRequest) bool {
+	return r.Method == other.Method && r.Path == other.Path && r.Form == other.Form
+}
+
+// readBody reads and closes the body of req.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+// normalizeForm returns body, a form, with its fields sorted by name.
+// The values of a field keep their order, which can matter, e.g. for the endpoints of /combined.
+func normalizeForm(body []byte) string {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return string(body)
+	}
+	return form.Encode()
+}
+
+// redactHeader returns a copy of header with the application key redacted.
+func redactHeader(header http.Header) http.Header {
+	h := header.Clone()
+	if len(h.Get("X-AYLIEN-TextAPI-Application-Key")) > 0 {
+		h.Set("X-AYLIEN-TextAPI-Application-Key", redactedKey)
+	}
+	return h
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapitest
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	textapi "github.com/AYLIEN/aylien_textapi_go"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "cassette.json")
+
+	s := NewServer(auth)
+	s.Enqueue(textapi.EndpointSentiment,
+		Reply{Body: textapi.SentimentResponse{Polarity: "negative"}},
+		Reply{Body: textapi.SentimentResponse{Polarity: "positive"}},
+	)
+	recorder, err := NewRecorder(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := s.NewClient(textapi.WithTransport(recorder))
+	for i := 0; i < 2; i++ {
+		if _, err := c.Sentiment(&textapi.SentimentParams{Text: "text", Mode: textapi.SentimentModeDocument}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), redactedKey) || strings.Contains(string(data), `"key"`) {
+		t.Errorf("application key not redacted:\n%s", data)
+	}
+
+	recorder, err = NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ = textapi.NewClient(auth, false, textapi.WithBaseURL(s.URL), textapi.WithTransport(recorder))
+	for _, polarity := range []string{"negative", "positive", "positive"} {
+		r, err := c.Sentiment(&textapi.SentimentParams{Text: "text", Mode: textapi.SentimentModeDocument})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Polarity != polarity {
+			t.Errorf("replayed polarity %q, want %q", r.Polarity, polarity)
+		}
+	}
+
+	if _, err := c.Sentiment(&textapi.SentimentParams{Text: "other text"}); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestRecorderNormalizedForm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	os.WriteFile(path, []byte(`{"interactions": [{
+		"request": {"method": "POST", "path": "/combined", "form": "endpoint=sentiment&endpoint=language&text=text"},
+		"response": {"status": 200, "body": "{\"text\": \"text\", \"results\": []}"}
+	}]}`), 0o600)
+
+	recorder, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest("POST", "http://example.com/combined",
+		strings.NewReader("text=text&endpoint=sentiment&endpoint=language"))
+	res, err := recorder.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	req, _ = http.NewRequest("POST", "http://example.com/combined",
+		strings.NewReader("text=text&endpoint=language&endpoint=sentiment"))
+	if _, err := recorder.RoundTrip(req); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("endpoints order not matched, got %v", err)
+	}
+}
+
+func TestRecorderModes(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewRecorder(filepath.Join(dir, "missing.json"), ModeReplay, nil); err == nil {
+		t.Error("expected an error for a missing cassette")
+	}
+
+	s := NewServer(auth)
+	defer s.Close()
+	path := filepath.Join(dir, "passthrough.json")
+	recorder, err := NewRecorder(path, ModePassthrough, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := s.NewClient(textapi.WithTransport(recorder))
+	if _, err := c.Language(&textapi.LanguageParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("cassette written in passthrough mode: %v", err)
+	}
+	s.AssertRequests(t, textapi.EndpointLanguage)
+}
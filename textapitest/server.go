@@ -0,0 +1,380 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	textapi "github.com/AYLIEN/aylien_textapi_go"
+)
+
+// EndpointCombined is the endpoint of textapi.Client.Combined.
+const EndpointCombined textapi.Endpoint = "combined"
+
+// A Reply is the answer of a Server to a request.
+type Reply struct {
+	// Status is the HTTP status of the reply. The zero Status is 200.
+	Status int
+
+	// Header holds headers added to the reply.
+	// They take precedence over the X-RateLimit-* headers of the Server.
+	Header http.Header
+
+	// Body is sent as is if it is a string or a []byte,
+	// and encoded as JSON otherwise. A nil Body is sent as an empty JSON object.
+	Body interface{}
+
+	// Delay is waited before replying, or until the request is cancelled.
+	Delay time.Duration
+}
+
+// ErrorReply returns a Reply with status and the JSON error message of the Text API.
+func ErrorReply(status int, message string) Reply {
+	return Reply{Status: status, Body: textapi.Error{Message: message}}
+}
+
+// A Request is a request received by a Server.
+type Request struct {
+	// Endpoint is the path of the request, without its leading slash, e.g. sentiment.
+	Endpoint textapi.Endpoint
+
+	// Form is the form sent with the request.
+	Form url.Values
+
+	// Header is the header of the request.
+	Header http.Header
+
+	// Status is the HTTP status the request was answered with.
+	Status int
+}
+
+// A Server is a fake Text API, serving every endpoint called by textapi.Client.
+//
+// Each endpoint answers with the replies queued with Enqueue, in order,
+// then with the reply set with Respond, Fail or SetReply, and by default
+// with an empty response, echoing the text sent where the endpoint does.
+// /combined answers with a result for each endpoint requested, taken from
+// the replies set for those endpoints.
+//
+// A Server is safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the server, to be used with textapi.WithBaseURL.
+	URL string
+
+	server *httptest.Server
+	auth   textapi.Auth
+
+	mu         sync.Mutex
+	replies    map[textapi.Endpoint]Reply
+	queues     map[textapi.Endpoint][]Reply
+	rateLimits textapi.RateLimits
+	requests   []Request
+}
+
+// NewServer starts and returns a Server that only accepts the requests
+// authenticated with auth, and rejects the others with a 403 status.
+// If auth is zero, every request is accepted. The caller must call Close
+// when finished, to shut it down.
+func NewServer(auth textapi.Auth) *Server {
+	s := &Server{
+		auth:    auth,
+		replies: map[textapi.Endpoint]Reply{},
+		queues:  map[textapi.Endpoint][]Reply{},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts down s, blocking until all outstanding requests have completed.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// NewClient returns a client calling s with the auth of s, and opts.
+func (s *Server) NewClient(opts ...textapi.Option) (*textapi.Client, error) {
+	opts = append([]textapi.Option{textapi.WithBaseURL(s.URL)}, opts...)
+	return textapi.NewClient(s.auth, false, opts...)
+}
+
+// SetReply makes endpoint answer with r, once the replies queued for it are used.
+func (s *Server) SetReply(endpoint textapi.Endpoint, r Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies[endpoint] = r
+}
+
+// Respond makes endpoint answer with body, e.g. a *textapi.SentimentResponse, see SetReply.
+func (s *Server) Respond(endpoint textapi.Endpoint, body interface{}) {
+	s.SetReply(endpoint, Reply{Body: body})
+}
+
+// Fail makes endpoint fail with status and message, see SetReply.
+func (s *Server) Fail(endpoint textapi.Endpoint, status int, message string) {
+	s.SetReply(endpoint, ErrorReply(status, message))
+}
+
+// Enqueue queues replies to be answered once each, in order, by endpoint.
+func (s *Server) Enqueue(endpoint textapi.Endpoint, replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queues[endpoint] = append(s.queues[endpoint], replies...)
+}
+
+// SetRateLimits makes s send r in the X-RateLimit-* headers of its replies.
+// Each request authenticated then decrements r.Remaining, and once it is zero,
+// requests are rejected with a 429 status. A zero r.Limit sends no headers.
+func (s *Server) SetRateLimits(r textapi.RateLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimits = r
+}
+
+// Requests returns the requests received by s, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Reset forgets the requests received by s, the replies set and queued,
+// and the rate limits.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.replies = map[textapi.Endpoint]Reply{}
+	s.queues = map[textapi.Endpoint][]Reply{}
+	s.rateLimits = textapi.RateLimits{}
+}
+
+// AssertRequests reports an error to t unless the endpoints requested from s,
+// in order, are endpoints.
+func (s *Server) AssertRequests(t testing.TB, endpoints ...textapi.Endpoint) {
+	t.Helper()
+	requests := s.Requests()
+	got := make([]textapi.Endpoint, len(requests))
+	for i, r := range requests {
+		got[i] = r.Endpoint
+	}
+	if fmt.Sprint(got) != fmt.Sprint(endpoints) {
+		t.Errorf("textapitest: requested endpoints %v, want %v", got, endpoints)
+	}
+}
+
+// AssertForm reports an error to t unless the last request to endpoint
+// was sent with the form field name set to values.
+func (s *Server) AssertForm(t testing.TB, endpoint textapi.Endpoint, name string, values ...string) {
+	t.Helper()
+	requests := s.Requests()
+	for i := len(requests) - 1; i >= 0; i-- {
+		if requests[i].Endpoint != endpoint {
+			continue
+		}
+		if got := requests[i].Form[name]; fmt.Sprint(got) != fmt.Sprint(values) {
+			t.Errorf("textapitest: %s sent with %s=%v, want %v", endpoint, name, got, values)
+		}
+		return
+	}
+	t.Errorf("textapitest: %s not requested", endpoint)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	endpoint := textapi.Endpoint(strings.TrimPrefix(req.URL.Path, "/"))
+
+	r := s.reply(req, endpoint)
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Endpoint: endpoint,
+		Form:     req.PostForm,
+		Header:   req.Header.Clone(),
+		Status:   r.Status,
+	})
+	s.mu.Unlock()
+
+	if r.Delay > 0 {
+		select {
+		case <-time.After(r.Delay):
+		case <-req.Context().Done():
+			return
+		}
+	}
+
+	body, err := marshal(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for name, values := range r.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(r.Status)
+	w.Write(body)
+}
+
+// reply returns the reply of s to req, sent to endpoint, with its status and rate limits set.
+func (s *Server) reply(req *http.Request, endpoint textapi.Endpoint) Reply {
+	switch {
+	case req.Method != http.MethodPost:
+		return ErrorReply(http.StatusMethodNotAllowed, "method not allowed")
+	case !endpoint.Valid() && endpoint != EndpointCombined:
+		return ErrorReply(http.StatusNotFound, "not found")
+	case !s.authenticated(req):
+		return ErrorReply(http.StatusForbidden, "Authentication parameters missing")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var r Reply
+	if s.rateLimits.Limit > 0 && s.rateLimits.Remaining <= 0 {
+		r = ErrorReply(http.StatusTooManyRequests, "Usage limits are exceeded")
+	} else {
+		if s.rateLimits.Limit > 0 {
+			s.rateLimits.Remaining--
+		}
+		r = s.next(endpoint, req.PostForm)
+	}
+	if r.Status == 0 {
+		r.Status = http.StatusOK
+	}
+
+	header := http.Header{}
+	if s.rateLimits.Limit > 0 {
+		header.Set("X-RateLimit-Limit", strconv.Itoa(s.rateLimits.Limit))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(s.rateLimits.Remaining))
+		header.Set("X-RateLimit-Reset", strconv.Itoa(s.rateLimits.Reset))
+	}
+	for name, values := range r.Header {
+		header[name] = values
+	}
+	r.Header = header
+	return r
+}
+
+// authenticated returns whether req is sent with the auth of s.
+func (s *Server) authenticated(req *http.Request) bool {
+	if s.auth == (textapi.Auth{}) {
+		return true
+	}
+	return req.Header.Get("X-AYLIEN-TextAPI-Application-ID") == s.auth.ApplicationID &&
+		req.Header.Get("X-AYLIEN-TextAPI-Application-Key") == s.auth.ApplicationKey
+}
+
+// next returns the next reply of endpoint to a request sent with form.
+// s.mu must be held.
+func (s *Server) next(endpoint textapi.Endpoint, form url.Values) Reply {
+	if queue := s.queues[endpoint]; len(queue) > 0 {
+		s.queues[endpoint] = queue[1:]
+		return queue[0]
+	}
+	if r, ok := s.replies[endpoint]; ok {
+		return r
+	}
+	if endpoint == EndpointCombined {
+		return s.combined(form)
+	}
+	return Reply{Body: defaultResponse(endpoint, form)}
+}
+
+// combined returns the reply of /combined to a request sent with form,
+// made of the replies set for the endpoints it requests. s.mu must be held.
+func (s *Server) combined(form url.Values) Reply {
+	type result struct {
+		Endpoint string      `json:"endpoint"`
+		Result   interface{} `json:"result"`
+	}
+	var results []result
+	for _, e := range form["endpoint"] {
+		endpoint := textapi.Endpoint(e)
+		body := defaultResponse(endpoint, form)
+		if r, ok := s.replies[endpoint]; ok {
+			if r.Status != 0 && r.Status != http.StatusOK {
+				return r
+			}
+			data, err := marshal(r.Body)
+			if err != nil {
+				return ErrorReply(http.StatusInternalServerError, err.Error())
+			}
+			body = json.RawMessage(data)
+		}
+		results = append(results, result{Endpoint: e, Result: body})
+	}
+	return Reply{Body: map[string]interface{}{"text": form.Get("text"), "results": results}}
+}
+
+// defaultResponse returns the empty response of endpoint to a request sent with form.
+func defaultResponse(endpoint textapi.Endpoint, form url.Values) interface{} {
+	text := form.Get("text")
+	switch endpoint {
+	case textapi.EndpointExtract:
+		return textapi.ExtractResponse{}
+	case textapi.EndpointSummarize:
+		return textapi.SummarizeResponse{Text: text}
+	case textapi.EndpointConcepts:
+		return textapi.ConceptsResponse{Text: text, Language: form.Get("language")}
+	case textapi.EndpointEntities:
+		return textapi.EntitiesResponse{Text: text}
+	case textapi.EndpointHashtags:
+		return textapi.HashtagsResponse{Text: text, Language: form.Get("language")}
+	case textapi.EndpointLanguage:
+		return textapi.LanguageResponse{Text: text}
+	case textapi.EndpointSentiment:
+		return textapi.SentimentResponse{Text: text}
+	case textapi.EndpointClassify:
+		return textapi.ClassifyResponse{Text: text, Language: form.Get("language")}
+	case textapi.EndpointUnsupervisedClassify:
+		return textapi.UnsupervisedClassifyResponse{Text: text}
+	case textapi.EndpointClassifyIABQAG, textapi.EndpointClassifyIPTC:
+		return textapi.ClassifyByTaxonomyResponse{
+			Text:     text,
+			Language: form.Get("language"),
+			Taxonomy: strings.TrimPrefix(string(endpoint), "classify/"),
+		}
+	case textapi.EndpointRelated:
+		return textapi.RelatedResponse{Phrase: form.Get("phrase")}
+	case textapi.EndpointImageTags:
+		return textapi.ImageTagsResponse{Image: form.Get("url")}
+	case textapi.EndpointMicroformats:
+		return textapi.MicroformatsResponse{}
+	}
+	return struct{}{}
+}
+
+// marshal returns body as sent by a Reply.
+func marshal(body interface{}) ([]byte, error) {
+	switch b := body.(type) {
+	case nil:
+		return []byte("{}"), nil
+	case string:
+		return []byte(b), nil
+	case []byte:
+		return b, nil
+	}
+	return json.Marshal(body)
+}
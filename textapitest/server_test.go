@@ -0,0 +1,179 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapitest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	textapi "github.com/AYLIEN/aylien_textapi_go"
+)
+
+var auth = textapi.Auth{ApplicationID: "id", ApplicationKey: "key"}
+
+func TestServerEndpoints(t *testing.T) {
+	s := NewServer(auth)
+	defer s.Close()
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, call := range []func() error{
+		func() error {
+			_, err := c.ExtractContext(ctx, &textapi.ExtractParams{URL: "http://example.com"})
+			return err
+		},
+		func() error {
+			_, err := c.SummarizeContext(ctx, &textapi.SummarizeParams{URL: "http://example.com"})
+			return err
+		},
+		func() error { _, err := c.ConceptsContext(ctx, &textapi.ConceptsParams{Text: "text"}); return err },
+		func() error { _, err := c.EntitiesContext(ctx, &textapi.EntitiesParams{Text: "text"}); return err },
+		func() error { _, err := c.HashtagsContext(ctx, &textapi.HashtagsParams{Text: "text"}); return err },
+		func() error { _, err := c.LanguageContext(ctx, &textapi.LanguageParams{Text: "text"}); return err },
+		func() error { _, err := c.SentimentContext(ctx, &textapi.SentimentParams{Text: "text"}); return err },
+		func() error { _, err := c.ClassifyContext(ctx, &textapi.ClassifyParams{Text: "text"}); return err },
+		func() error {
+			_, err := c.UnsupervisedClassifyContext(ctx, &textapi.UnsupervisedClassifyParams{Text: "text", Classes: []string{"a", "b"}})
+			return err
+		},
+		func() error {
+			_, err := c.ClassifyByTaxonomyContext(ctx, &textapi.ClassifyByTaxonomyParams{Text: "text", Taxonomy: textapi.TaxonomyIPTCSubjectCode})
+			return err
+		},
+		func() error { _, err := c.RelatedContext(ctx, &textapi.RelatedParams{Phrase: "phrase"}); return err },
+		func() error {
+			_, err := c.ImageTagsContext(ctx, &textapi.ImageTagsParams{URL: "http://example.com/a.png"})
+			return err
+		},
+		func() error {
+			_, err := c.MicroformatsContext(ctx, &textapi.MicroformatsParams{URL: "http://example.com"})
+			return err
+		},
+		func() error {
+			r, err := c.CombinedContext(ctx, &textapi.CombinedParams{
+				Text:      "text",
+				Endpoints: []textapi.Endpoint{textapi.EndpointSentiment, textapi.EndpointLanguage},
+			})
+			if err == nil {
+				err = r.EndpointErr(textapi.EndpointLanguage)
+			}
+			return err
+		},
+	} {
+		if err := call(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	s.AssertRequests(t,
+		textapi.EndpointExtract, textapi.EndpointSummarize, textapi.EndpointConcepts, textapi.EndpointEntities,
+		textapi.EndpointHashtags, textapi.EndpointLanguage, textapi.EndpointSentiment, textapi.EndpointClassify,
+		textapi.EndpointUnsupervisedClassify, textapi.EndpointClassifyIPTC, textapi.EndpointRelated,
+		textapi.EndpointImageTags, textapi.EndpointMicroformats, EndpointCombined,
+	)
+	s.AssertForm(t, textapi.EndpointUnsupervisedClassify, "class", "a", "b")
+}
+
+func TestServerAuth(t *testing.T) {
+	s := NewServer(auth)
+	defer s.Close()
+	c, _ := textapi.NewClient(textapi.Auth{ApplicationID: "id", ApplicationKey: "wrong"}, false, textapi.WithBaseURL(s.URL))
+
+	_, err := c.Sentiment(&textapi.SentimentParams{Text: "text"})
+	if !errors.Is(err, textapi.ErrUnauthorized) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if r := s.Requests(); len(r) != 1 || r[0].Status != http.StatusForbidden {
+		t.Errorf("unexpected requests %+v", r)
+	}
+}
+
+func TestServerReplies(t *testing.T) {
+	s := NewServer(auth)
+	defer s.Close()
+	c, _ := s.NewClient()
+
+	s.Respond(textapi.EndpointSentiment, textapi.SentimentResponse{Polarity: "positive"})
+	s.Enqueue(textapi.EndpointSentiment, ErrorReply(http.StatusBadRequest, "invalid text"))
+
+	if _, err := c.Sentiment(&textapi.SentimentParams{Text: "text"}); !errors.Is(err, textapi.ErrInvalidInput) {
+		t.Errorf("unexpected error %v", err)
+	}
+	r, err := c.Sentiment(&textapi.SentimentParams{Text: "text"})
+	if err != nil || r.Polarity != "positive" {
+		t.Errorf("unexpected response %+v and error %v", r, err)
+	}
+
+	s.Fail(textapi.EndpointEntities, http.StatusBadRequest, "invalid url")
+	combined, err := c.Combined(&textapi.CombinedParams{
+		Text:      "text",
+		Endpoints: []textapi.Endpoint{textapi.EndpointSentiment, textapi.EndpointConcepts},
+	})
+	if err != nil || combined.Sentiment.Polarity != "positive" {
+		t.Errorf("unexpected response %+v and error %v", combined, err)
+	}
+	if _, err := c.Combined(&textapi.CombinedParams{
+		Text:      "text",
+		Endpoints: []textapi.Endpoint{textapi.EndpointSentiment, textapi.EndpointEntities},
+	}); !errors.Is(err, textapi.ErrInvalidInput) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	s.Reset()
+	if r, _ := c.Sentiment(&textapi.SentimentParams{Text: "text"}); r.Polarity != "" || r.Text != "text" {
+		t.Errorf("unexpected response after reset %+v", r)
+	}
+}
+
+func TestServerRateLimits(t *testing.T) {
+	s := NewServer(auth)
+	defer s.Close()
+	c, _ := s.NewClient()
+
+	s.SetRateLimits(textapi.RateLimits{Limit: 10, Remaining: 1, Reset: 1420479141})
+	var meta textapi.Response
+	if _, err := c.Language(&textapi.LanguageParams{Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	if r := c.LastRateLimits(); r.Limit != 10 || r.Remaining != 0 || r.Reset != 1420479141 {
+		t.Errorf("unexpected rate limits %+v", r)
+	}
+
+	_, err := c.LanguageContext(context.Background(), &textapi.LanguageParams{Text: "text"}, textapi.CaptureResponse(&meta))
+	if !errors.Is(err, textapi.ErrRateLimited) || meta.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestServerDelay(t *testing.T) {
+	s := NewServer(auth)
+	defer s.Close()
+	c, _ := s.NewClient()
+
+	s.SetReply(textapi.EndpointHashtags, Reply{Delay: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.HashtagsContext(ctx, &textapi.HashtagsParams{Text: "text"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
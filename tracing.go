@@ -0,0 +1,116 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// A Tracer creates a span around every call made by a client.
+//
+// Its methods mirror OpenTelemetry's trace.Tracer.Start and
+// propagation.TextMapPropagator.Inject, so an OpenTelemetry tracer can be
+// adapted without this package depending on it.
+type Tracer interface {
+	// Start starts a span named spanName, a child of the span in ctx if any,
+	// and returns a context holding the new span.
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+
+	// Inject writes the trace context held by ctx into the headers of an outgoing request.
+	Inject(ctx context.Context, header http.Header)
+}
+
+// A Span is a traced call, see Tracer.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// An Attribute is a key-value pair describing a span.
+// Value is a string, an int or a bool.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attributes set on the span of every call.
+const (
+	// AttributeEndpoint is the path of the endpoint called, e.g. /classify/iab-qag.
+	AttributeEndpoint = "textapi.endpoint"
+
+	// AttributeInputType is the kind of document analyzed: text, url or html.
+	AttributeInputType = "textapi.input_type"
+
+	// AttributeTextLength is the length in bytes of the text or html analyzed.
+	AttributeTextLength = "textapi.text_length"
+
+	// AttributeLanguage is the language parameter of the call, if any.
+	AttributeLanguage = "textapi.language"
+
+	// AttributeStatusCode is the HTTP status of the last attempt.
+	AttributeStatusCode = "http.status_code"
+
+	// AttributeRetryCount is the number of retries of the call.
+	AttributeRetryCount = "textapi.retry_count"
+)
+
+// WithTracer makes the client trace its calls with tracer.
+// Each span is named after the endpoint path, e.g. /sentiment or /combined.
+func WithTracer(tracer Tracer) Option {
+	return func(c *Client) error {
+		if tracer == nil {
+			return errors.New("tracer must not be nil")
+		}
+		c.tracer = tracer
+		return nil
+	}
+}
+
+// callAttributes returns the attributes describing the input of call.
+func callAttributes(call *Call) []Attribute {
+	attrs := []Attribute{{AttributeEndpoint, call.Endpoint}}
+	for _, input := range []string{"text", "html", "url"} {
+		if v := call.Form.Get(input); len(v) > 0 {
+			attrs = append(attrs, Attribute{AttributeInputType, input})
+			if input != "url" {
+				attrs = append(attrs, Attribute{AttributeTextLength, len(v)})
+			}
+			break
+		}
+	}
+	if language := call.Form.Get("language"); len(language) > 0 {
+		attrs = append(attrs, Attribute{AttributeLanguage, language})
+	}
+	return attrs
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopTracer) Inject(ctx context.Context, header http.Header) {}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+func (noopSpan) RecordError(err error)            {}
+func (noopSpan) End()                             {}
@@ -0,0 +1,125 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (s *testSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *testSpan) RecordError(err error) { s.err = err }
+func (s *testSpan) End()                  { s.ended = true }
+
+type spanKey struct{}
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (tr *testTracer) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	span := &testSpan{name: spanName, attrs: make(map[string]interface{})}
+	tr.spans = append(tr.spans, span)
+	return context.WithValue(ctx, spanKey{}, len(tr.spans)), span
+}
+
+func (tr *testTracer) Inject(ctx context.Context, header http.Header) {
+	if id, ok := ctx.Value(spanKey{}).(int); ok {
+		header.Set("Traceparent", strconv.Itoa(id))
+	}
+}
+
+func TestTracing(t *testing.T) {
+	var traceparents []string
+	server, _ := newFlakyServer(1, http.StatusServiceUnavailable)
+	defer server.Close()
+	tracing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("Traceparent"))
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer tracing.Close()
+
+	tracer := &testTracer{}
+	c, _ := NewClient(auth, false, WithBaseURL(tracing.URL), WithTracer(tracer),
+		WithRetryPolicy(RetryPolicy{MinBackoff: time.Millisecond}))
+	params := &ClassifyByTaxonomyParams{Text: "some text", Taxonomy: "iab-qag", Language: "en"}
+	if _, err := c.ClassifyByTaxonomy(params); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Microformats(&MicroformatsParams{URL: "http://aylien.com/"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	expected := map[string]interface{}{
+		AttributeEndpoint:   "/classify/iab-qag",
+		AttributeInputType:  "text",
+		AttributeTextLength: 9,
+		AttributeLanguage:   "en",
+		AttributeStatusCode: 200,
+		AttributeRetryCount: 1,
+	}
+	if span.name != "/classify/iab-qag" || !span.ended || span.err != nil ||
+		fmt.Sprint(span.attrs) != fmt.Sprint(expected) {
+		t.Errorf("unexpected span %+v", span)
+	}
+	if tracer.spans[1].attrs[AttributeInputType] != "url" {
+		t.Errorf("unexpected span %+v", tracer.spans[1])
+	}
+	if fmt.Sprint(traceparents) != "[1 1 2]" {
+		t.Errorf("trace context not propagated: %v", traceparents)
+	}
+}
+
+func TestTracingError(t *testing.T) {
+	tracer := &testTracer{}
+	c, _ := NewClient(auth, false, WithBaseURL(testServerURL), WithTracer(tracer))
+	if _, err := c.Entities(&EntitiesParams{URL: "invalid"}); err == nil {
+		t.Fatal("did not return error")
+	}
+	if span := tracer.spans[0]; span.err == nil || span.attrs[AttributeStatusCode] != 400 {
+		t.Errorf("unexpected span %+v", span)
+	}
+
+	if _, err := NewClient(auth, false, WithTracer(nil)); err == nil {
+		t.Error("did not return error")
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// defaultMaxResponseSize is the largest response body accepted by default.
+const defaultMaxResponseSize = 10 << 20
+
+// maxDrainSize is how much of an unread response body is discarded
+// so that the connection can be reused.
+const maxDrainSize = 64 << 10
+
+// newTransport returns the transport shared by all requests of a client
+// that was not given its own http.Client or http.RoundTripper.
+// Unlike http.DefaultTransport, it keeps enough idle connections per host
+// for concurrent calls to the single Text API host to reuse them.
+func newTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   64,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// A limitedReader reads from r and fails with ErrResponseTooLarge
+// once more than n bytes have been read.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, ErrResponseTooLarge
+	}
+	return n, err
+}
+
+// closeBody drains what is left of body before closing it,
+// which lets the transport put the connection back in its pool.
+func closeBody(body io.ReadCloser) {
+	io.CopyN(io.Discard, body, maxDrainSize)
+	body.Close()
+}
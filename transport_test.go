@@ -0,0 +1,120 @@
+/*
+Copyright 2015 Aylien, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package textapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	text := strings.Repeat("a", 1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprintf(w, `{"text": %q}`, text)
+	}))
+	defer server.Close()
+
+	for _, baseURL := range []string{server.URL, server.URL + "/chunked"} {
+		c, _ := NewClient(auth, false, WithBaseURL(baseURL), WithMaxResponseSize(512))
+		_, err := c.Sentiment(&SentimentParams{Text: text})
+		if !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("%s: expected ErrResponseTooLarge, got %v", baseURL, err)
+		}
+
+		c, _ = NewClient(auth, false, WithBaseURL(baseURL), WithMaxResponseSize(2048))
+		sentiment, err := c.Sentiment(&SentimentParams{Text: text})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sentiment.Text != text {
+			t.Error("invalid response")
+		}
+	}
+}
+
+func TestSharedTransport(t *testing.T) {
+	c, _ := NewClient(auth, true)
+	if _, ok := c.httpClient.Transport.(*http.Transport); !ok {
+		t.Error("client must own a transport")
+	}
+	other, _ := NewClient(auth, true)
+	if c.httpClient.Transport == other.httpClient.Transport {
+		t.Error("clients must not share a transport")
+	}
+}
+
+func newBenchmarkServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sentiment":
+			fmt.Fprint(w, `{"text":"John is a very good football player!","polarity":"positive","polarity_confidence":0.97,"subjectivity":"subjective","subjectivity_confidence":0.99}`)
+		case "/concepts":
+			fmt.Fprint(w, `{"text":"Apple was founded by Steve Jobs.","language":"en","concepts":{"http://dbpedia.org/resource/Apple_Inc.":{"surfaceForms":[{"string":"Apple","score":0.99,"offset":0}],"types":["http://dbpedia.org/ontology/Company"],"support":12345},"http://dbpedia.org/resource/Steve_Jobs":{"surfaceForms":[{"string":"Steve Jobs","score":0.99,"offset":20}],"types":["http://dbpedia.org/ontology/Person"],"support":6789}}}`)
+		}
+	}))
+}
+
+func benchmarkConcurrentCalls(b *testing.B, opts ...Option) {
+	server := newBenchmarkServer()
+	defer server.Close()
+
+	c, _ := NewClient(auth, false, append([]Option{WithBaseURL(server.URL)}, opts...)...)
+	b.SetParallelism(8)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			var err error
+			if i%2 == 0 {
+				_, err = c.Sentiment(&SentimentParams{Text: "John is a very good football player!"})
+			} else {
+				_, err = c.Concepts(&ConceptsParams{Text: "Apple was founded by Steve Jobs."})
+			}
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkConcurrentCallsSharedTransport measures concurrent calls through
+// the transport a client owns by default.
+func BenchmarkConcurrentCallsSharedTransport(b *testing.B) {
+	benchmarkConcurrentCalls(b)
+}
+
+// BenchmarkConcurrentCallsDefaultTransport measures concurrent calls through
+// http.DefaultTransport, which keeps only two idle connections per host.
+func BenchmarkConcurrentCallsDefaultTransport(b *testing.B) {
+	benchmarkConcurrentCalls(b, WithTransport(http.DefaultTransport))
+}
+
+// BenchmarkConcurrentCallsNoKeepAlive measures concurrent calls that open
+// a new connection for every request.
+func BenchmarkConcurrentCallsNoKeepAlive(b *testing.B) {
+	benchmarkConcurrentCalls(b, WithTransport(&http.Transport{DisableKeepAlives: true}))
+}